@@ -2,24 +2,107 @@ package dsmr
 
 import (
 	"fmt"
-	"strings"
-
-	"github.com/snksoft/crc"
+	"hash"
+	"io"
 )
 
-func verifyChecksum(t *Telegram, raw string, opts *parseOptions) error {
+// Hash16 is the interface implemented by hash functions with a 16-bit sum.
+type Hash16 interface {
+	hash.Hash
+	io.StringWriter
+	Sum16() uint16
+}
+
+// crc16Table holds the CRC16/ARC values of all bytes, for the reversed
+// polynomial 0xA001.
+var crc16Table = func() (table [256]uint16) {
+	for i := range table {
+		crc := uint16(i)
+		for j := 0; j < 8; j++ {
+			if crc&1 == 1 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+		table[i] = crc
+	}
+
+	return
+}()
+
+type crc16 struct {
+	crc uint16
+}
+
+// NewHash returns a new hash computing the CRC16/ARC checksum that meters put
+// in the footer of a telegram.
+func NewHash() Hash16 {
+	return &crc16{}
+}
+
+func (h *crc16) Size() int      { return 2 }
+func (h *crc16) BlockSize() int { return 1 }
+func (h *crc16) Reset()         { h.crc = 0 }
+func (h *crc16) Sum16() uint16  { return h.crc }
+
+func (h *crc16) Sum(b []byte) []byte {
+	return append(b, byte(h.crc>>8), byte(h.crc))
+}
+
+func (h *crc16) Write(p []byte) (int, error) {
+	crc := h.crc
+	for _, c := range p {
+		crc = crc>>8 ^ crc16Table[byte(crc)^c]
+	}
+	h.crc = crc
+
+	return len(p), nil
+}
+
+func (h *crc16) WriteString(s string) (int, error) {
+	crc := h.crc
+	for i := 0; i < len(s); i++ {
+		crc = crc>>8 ^ crc16Table[byte(crc)^s[i]]
+	}
+	h.crc = crc
+
+	return len(s), nil
+}
+
+// Checksum returns the CRC16/ARC checksum of data. The checksum in the footer
+// of a telegram covers everything from the "/" of its header up to and
+// including the "!" of its footer.
+func Checksum(data []byte) uint16 {
+	h := crc16{}
+	_, _ = h.Write(data)
+
+	return h.crc
+}
+
+// verifyChecksum verifies the checksum in the footer of a telegram against the
+// checksum of raw, or against sum when it was computed while reading already.
+func verifyChecksum(t *Telegram, raw string, sum *uint16, opts *parseOptions) error {
 	// Only check footer if verifying is enabled and we found one while parsing
 	if !opts.verifyChecksum || t.Footer.Value == "" {
 		return nil
 	}
 
-	// Compute expected checksum from original message (including the "!" character).
-	msg, _, _ := strings.Cut(raw, "!")
-	checksum := fmt.Sprintf("%04X", crc.CalculateCRC(crc.CRC16, []byte(msg+"!")))
+	if sum == nil {
+		h := crc16{}
+		_, _ = h.WriteString(raw[:t.Footer.Pos.Offset+1])
+		sum = &h.crc
+	}
 
-	if t.Footer.Value != checksum {
-		return &ChecksumError{Unexpected: checksum, Expect: t.Footer.Value}
+	// The footer holds exactly four uppercase hexadecimal digits.
+	if t.Footer.Value != formatChecksum(*sum) {
+		return &ChecksumError{Unexpected: formatChecksum(*sum), Expect: t.Footer.Value}
 	}
 
 	return nil
 }
+
+// formatChecksum formats a checksum as it appears in a footer.
+func formatChecksum(sum uint16) string {
+	return fmt.Sprintf("%04X", sum)
+}
@@ -1,9 +1,11 @@
 package dsmr
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/alecthomas/assert/v2"
+	"github.com/alecthomas/participle/v2/lexer"
 )
 
 func TestValidChecksum(t *testing.T) {
@@ -12,8 +14,8 @@ func TestValidChecksum(t *testing.T) {
 		"0-0:0.0.0()\r\n" +
 		"!75B7\r\n"
 
-	telegram := &Telegram{Footer: &Footer{Value: "75B7"}}
-	err := verifyChecksum(telegram, raw, &parseOptions{verifyChecksum: true})
+	telegram := &Telegram{Footer: &Footer{Pos: lexer.Position{Offset: strings.Index(raw, "!")}, Value: "75B7"}}
+	err := verifyChecksum(telegram, raw, nil, &parseOptions{verifyChecksum: true})
 	assert.NoError(t, err)
 }
 
@@ -23,18 +25,51 @@ func TestInvalidChecksum(t *testing.T) {
 		"0-0:0.0.0()\r\n" +
 		"!1234\r\n"
 
-	telegram := &Telegram{Footer: &Footer{Value: "1234"}}
-	err := verifyChecksum(telegram, raw, &parseOptions{verifyChecksum: true})
+	telegram := &Telegram{Footer: &Footer{Pos: lexer.Position{Offset: strings.Index(raw, "!")}, Value: "1234"}}
+	err := verifyChecksum(telegram, raw, nil, &parseOptions{verifyChecksum: true})
 	assert.EqualError(t, err, "unexpected checksum \"75B7\" (expected \"1234\")")
 }
 
+func TestMalformedChecksum(t *testing.T) {
+	for _, footer := range []string{"75b7", "075B7"} {
+		raw := "" +
+			"/header\r\n" +
+			"0-0:0.0.0()\r\n" +
+			"!" + footer + "\r\n"
+
+		telegram := &Telegram{Footer: &Footer{Pos: lexer.Position{Offset: strings.Index(raw, "!")}, Value: footer}}
+		err := verifyChecksum(telegram, raw, nil, &parseOptions{verifyChecksum: true})
+		assert.EqualError(t, err, "unexpected checksum \"75B7\" (expected \""+footer+"\")", footer)
+	}
+}
+
 func TestIgnoreChecksum(t *testing.T) {
 	raw := "" +
 		"/header\r\n" +
 		"0-0:0.0.0()\r\n" +
 		"!1234\r\n"
 
-	telegram := &Telegram{Footer: &Footer{Value: "1234"}}
-	err := verifyChecksum(telegram, raw, &parseOptions{verifyChecksum: false})
+	telegram := &Telegram{Footer: &Footer{Pos: lexer.Position{Offset: strings.Index(raw, "!")}, Value: "1234"}}
+	err := verifyChecksum(telegram, raw, nil, &parseOptions{verifyChecksum: false})
 	assert.NoError(t, err)
 }
+
+func TestChecksum(t *testing.T) {
+	assert.Equal(t, uint16(0xBB3D), Checksum([]byte("123456789")))
+	assert.Equal(t, uint16(0x75B7), Checksum([]byte("/header\r\n0-0:0.0.0()\r\n!")))
+	assert.Equal(t, uint16(0), Checksum(nil))
+}
+
+func TestHash(t *testing.T) {
+	h := NewHash()
+	assert.Equal(t, 2, h.Size())
+
+	_, _ = h.Write([]byte("/header\r\n"))
+	_, _ = h.WriteString("0-0:0.0.0()\r\n!")
+	assert.Equal(t, uint16(0x75B7), h.Sum16())
+	assert.Equal(t, []byte{0x01, 0x75, 0xB7}, h.Sum([]byte{0x01}))
+
+	h.Reset()
+	_, _ = h.WriteString("123456789")
+	assert.Equal(t, uint16(0xBB3D), h.Sum16())
+}
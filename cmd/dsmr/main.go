@@ -0,0 +1,233 @@
+// Command dsmr reads telegrams of smart meters from files, stdin or a serial
+// P1 port and prints them as a table, as JSON or in the format of the meter.
+//
+// Usage:
+//
+//	dsmr [flags] [file or device ...]
+//
+// Without arguments, or with "-", telegrams are read from stdin. Serial
+// devices are opened with the line settings detected from their telegrams.
+//
+// With -listen, telegrams are not printed but the latest one is exposed as
+// Prometheus metrics on /metrics, until the command is interrupted.
+//
+// The exit code is 0 when all telegrams were read, 1 on invalid usage or when
+// reading failed, 2 when a telegram could not be parsed and 3 when a telegram
+// had an invalid checksum. The highest code applies when several telegrams
+// failed.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/robinvdvleuten/dsmr"
+	"github.com/robinvdvleuten/dsmr/p1"
+	"github.com/robinvdvleuten/dsmr/prometheus"
+)
+
+const (
+	exitOK = iota
+	exitError
+	exitParse
+	exitChecksum
+)
+
+// detectTimeout is the time to wait for a telegram with each of the line
+// settings of a serial device.
+const detectTimeout = 15 * time.Second
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with the given arguments and returns its exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("dsmr", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: dsmr [flags] [file or device ...]")
+		flags.PrintDefaults()
+	}
+
+	format := flags.String("format", "table", "output `format`: table, json or raw")
+	checksum := flags.Bool("checksum", true, "verify the checksum of telegrams")
+	count := flags.Int("n", 0, "stop after reading `count` telegrams, 0 reads all")
+	listen := flags.String("listen", "", "expose Prometheus metrics on `address` instead of printing")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	printTelegram, ok := printers[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "dsmr: unknown format %q\n", *format)
+		return exitError
+	}
+
+	names := flags.Args()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	c := &command{
+		stdout:  stdout,
+		stderr:  stderr,
+		print:   printTelegram,
+		options: []dsmr.Option{dsmr.VerifyChecksum(*checksum)},
+		count:   *count,
+	}
+
+	var served chan error
+	if *listen != "" {
+		ln, err := net.Listen("tcp", *listen)
+		if err != nil {
+			fmt.Fprintf(stderr, "dsmr: %s\n", err)
+			return exitError
+		}
+
+		c.exporter = prometheus.NewExporter()
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", c.exporter)
+
+		served = make(chan error, 1)
+		go func() { served <- http.Serve(ln, mux) }()
+	}
+
+	for _, name := range names {
+		if err := c.readFile(name, stdin); err != nil {
+			fmt.Fprintf(stderr, "dsmr: %s\n", err)
+			return exitError
+		}
+
+		if c.done() {
+			break
+		}
+	}
+
+	// Keep exposing the latest telegram once all are read.
+	if served != nil {
+		fmt.Fprintf(stderr, "dsmr: %s\n", <-served)
+		return exitError
+	}
+
+	return c.code
+}
+
+type command struct {
+	stdout, stderr io.Writer
+	print          printer
+	options        []dsmr.Option
+	count          int // Number of telegrams to read, 0 for all.
+
+	// exporter exposes the telegrams instead of printing them, if set.
+	exporter *prometheus.Exporter
+
+	n    int // Number of telegrams read.
+	code int // Exit code of the worst failure so far.
+}
+
+func (c *command) done() bool {
+	return c.count > 0 && c.n >= c.count
+}
+
+// readFile reads the telegrams of a file, stdin for "-" or a serial device.
+func (c *command) readFile(name string, stdin io.Reader) error {
+	if name == "-" {
+		return c.readTelegrams(name, stdin)
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeCharDevice != 0 {
+		port, _, err := p1.Detect(name, detectTimeout, c.options...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		defer port.Close()
+
+		return c.readTelegrams(name, port)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return c.readTelegrams(name, f)
+}
+
+// readTelegrams prints the telegrams read from r, and the errors of those that
+// could not be parsed. Only a failure to read is returned.
+func (c *command) readTelegrams(name string, r io.Reader) error {
+	reader := dsmr.NewReader(r, c.options...)
+
+	for !c.done() {
+		telegram, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			code := failure(err)
+			if code == exitError {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+
+			fmt.Fprintf(c.stderr, "dsmr: %s: %s\n", name, err)
+			if c.exporter != nil {
+				c.exporter.Observe(nil, err)
+			}
+			if code > c.code {
+				c.code = code
+			}
+
+			// A telegram cut off at the end of the stream ends it.
+			if err == io.ErrUnexpectedEOF {
+				return nil
+			}
+
+			continue
+		}
+
+		c.n++
+		if c.exporter != nil {
+			c.exporter.Observe(telegram, nil)
+			continue
+		}
+
+		if err := c.print(c.stdout, telegram, reader.Frame()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// failure returns the exit code for an error of reading a telegram.
+func failure(err error) int {
+	var (
+		checksumErr *dsmr.ChecksumError
+		telegramErr *dsmr.TelegramError
+	)
+
+	switch {
+	case errors.As(err, &checksumErr):
+		return exitChecksum
+	case errors.As(err, &telegramErr), err == io.ErrUnexpectedEOF:
+		return exitParse
+	}
+
+	return exitError
+}
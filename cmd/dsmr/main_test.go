@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/robinvdvleuten/dsmr"
+	"github.com/robinvdvleuten/dsmr/prometheus"
+)
+
+const telegram = "" +
+	"/KFM5KAIFA-METER\r\n" +
+	"\r\n" +
+	"1-3:0.2.8(42)\r\n" +
+	"0-0:1.0.0(161113205757W)\r\n" +
+	"1-0:1.8.1(001581.123*kWh)\r\n" +
+	"0-1:24.2.1(161129200000W)(00981.443*m3)\r\n" +
+	"!"
+
+// withChecksum completes the footer of raw with its checksum.
+func withChecksum(raw string) string {
+	return raw + fmt.Sprintf("%04X", dsmr.Checksum([]byte(raw))) + "\r\n"
+}
+
+func TestRunTable(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(nil, strings.NewReader(withChecksum(telegram)), &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "", stderr.String())
+	assert.Contains(t, stdout.String(), "HEADER      KFM5KAIFA-METER")
+	assert.Contains(t, stdout.String(), "VERSION     4.2")
+	assert.Contains(t, stdout.String(), "1-3:0.2.8   Version information               42\n")
+	assert.Contains(t, stdout.String(), "1-0:1.8.1   Electricity delivered (tariff 1)  1581.123 kWh")
+	assert.Contains(t, stdout.String(), "0-1:24.2.1  MBus last 5-minute value          981.443 m3 at 2016-11-29T20:00:00+01:00")
+}
+
+func TestRunJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	raw := withChecksum(telegram)
+	code := run([]string{"-format", "json"}, strings.NewReader(raw+raw), &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], `{"header":{"value":"KFM5KAIFA-METER"},"data":[`))
+}
+
+func TestRunCount(t *testing.T) {
+	var stdout bytes.Buffer
+	raw := withChecksum(telegram)
+	code := run([]string{"-format", "raw", "-n", "1"}, strings.NewReader(raw+raw), &stdout, &bytes.Buffer{})
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, raw, stdout.String())
+}
+
+func TestRunFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "telegram.txt")
+	assert.NoError(t, os.WriteFile(name, []byte(withChecksum(telegram)), 0o644))
+
+	var stdout bytes.Buffer
+	code := run([]string{"-format", "json", name}, nil, &stdout, &bytes.Buffer{})
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout.String(), `"value":"KFM5KAIFA-METER"`)
+
+	var stderr bytes.Buffer
+	code = run([]string{filepath.Join(t.TempDir(), "missing.txt")}, nil, &stdout, &stderr)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr.String(), "no such file or directory")
+}
+
+func TestRunChecksum(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-format", "raw"}, strings.NewReader(telegram+"0000\r\n"), &stdout, &stderr)
+
+	assert.Equal(t, exitChecksum, code)
+	assert.Equal(t, "", stdout.String())
+	assert.Contains(t, stderr.String(), `dsmr: -: unexpected checksum`)
+
+	// Telegrams are printed as they were read, with their invalid checksum.
+	code = run([]string{"-format", "raw", "-checksum=false"}, strings.NewReader(telegram+"0000\r\n"), &stdout, &stderr)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, telegram+"0000\r\n", stdout.String())
+}
+
+func TestRunParseError(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	raw := "/header\r\n1-0:1.8.1(0001*kWh)()\r\n!\r\n" + withChecksum(telegram)
+	code := run([]string{"-format", "raw"}, strings.NewReader(raw), &stdout, &stderr)
+
+	assert.Equal(t, exitParse, code)
+	assert.Contains(t, stderr.String(), "unknown value of 1-0:1.8.1")
+	assert.Contains(t, stdout.String(), "/KFM5KAIFA-METER")
+}
+
+func TestRunUsage(t *testing.T) {
+	var stderr bytes.Buffer
+	code := run([]string{"-format", "xml"}, nil, &bytes.Buffer{}, &stderr)
+
+	assert.Equal(t, exitError, code)
+	assert.Equal(t, "dsmr: unknown format \"xml\"\n", stderr.String())
+
+	code = run([]string{"-unknown"}, nil, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.Equal(t, exitError, code)
+}
+
+func TestReadExporter(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	c := &command{stdout: &stdout, stderr: &stderr, exporter: prometheus.NewExporter()}
+
+	raw := "/header\r\n1-0:1.8.1(0001*kWh)()\r\n!\r\n" + withChecksum(telegram)
+	assert.NoError(t, c.readTelegrams("-", strings.NewReader(raw)))
+	assert.Equal(t, "", stdout.String())
+
+	var metrics bytes.Buffer
+	_, err := c.exporter.WriteTo(&metrics)
+	assert.NoError(t, err)
+	assert.Contains(t, metrics.String(), "dsmr_parse_errors_total 1\n")
+	assert.Contains(t, metrics.String(), "dsmr_telegrams_total 1\n")
+	assert.Contains(t, metrics.String(), `dsmr_electricity_delivered_kwh_total{tariff="1"} 1581.123`)
+}
+
+func TestRunListenError(t *testing.T) {
+	var stderr bytes.Buffer
+	code := run([]string{"-listen", "invalid:address:0"}, nil, &bytes.Buffer{}, &stderr)
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr.String(), "dsmr: listen tcp")
+}
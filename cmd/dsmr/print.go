@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/robinvdvleuten/dsmr"
+)
+
+// printer prints a telegram to w, which was read from frame.
+type printer func(w io.Writer, t *dsmr.Telegram, frame []byte) error
+
+var printers = map[string]printer{
+	"table": printTable,
+	"json":  printJSON,
+	"raw":   printRaw,
+}
+
+// printJSON prints a telegram as JSON on a single line, without positions.
+func printJSON(w io.Writer, t *dsmr.Telegram, _ []byte) error {
+	data, err := dsmr.JSONOptions{}.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// printRaw prints a telegram as it was sent by the meter, including its
+// checksum and padding.
+func printRaw(w io.Writer, _ *dsmr.Telegram, frame []byte) error {
+	_, err := w.Write(frame)
+	return err
+}
+
+// printTable prints the objects of a telegram as a table, with the description
+// of their OBIS code.
+func printTable(w io.Writer, t *dsmr.Telegram, _ []byte) error {
+	version := t.Version()
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "HEADER\t%s\t\n", t.Header.Value)
+	fmt.Fprintf(tw, "VERSION\t%s\t\n", version)
+
+	for _, obj := range t.Data {
+		var info dsmr.OBISInfo
+		if obj.OBIS != nil {
+			if code, err := obj.OBIS.Code(); err == nil {
+				info, _ = dsmr.LookupOBIS(code)
+			}
+		}
+
+		value := formatValue(obj.Value)
+		if s, ok := obj.Value.(*dsmr.String); ok && info.Kind == dsmr.KindText {
+			value = dsmr.DecodeText(s.Value, version).Value
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", obj.Key(), info.Description, value)
+	}
+
+	fmt.Fprintf(tw, "FOOTER\t%s\t\n", t.Footer.Value)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// formatValue formats a value for humans.
+func formatValue(v dsmr.Value) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case *dsmr.String:
+		return v.Value
+	case *dsmr.OBIS:
+		return v.Value
+	case *dsmr.Number:
+		return v.Value.Text('f', -1)
+	case *dsmr.Measurement:
+		return v.Value.Value.Text('f', -1) + " " + v.Unit.Value
+	case *dsmr.Timestamp:
+		return formatTime(v.Time())
+	case *dsmr.LastCapture:
+		return formatValue(v.Value) + " at " + formatTime(v.Timestamp.Time())
+	case *dsmr.LegacyLastCapture:
+		return v.Value.Value.Value.Text('f', -1) + " " + v.Value.Unit.Value + " at " + formatTime(v.Time())
+	case *dsmr.EventLog:
+		events := make([]string, len(v.Value))
+		for i, event := range v.Value {
+			events[i] = formatValue(event.Value) + " until " + formatTime(event.Timestamp.Time())
+		}
+		return strings.Join(events, ", ")
+	case *dsmr.PeakLog:
+		peaks := make([]string, len(v.Value))
+		for i, peak := range v.Value {
+			peaks[i] = formatValue(peak.Value) + " at " + formatTime(peak.Timestamp.Time())
+		}
+		return strings.Join(peaks, ", ")
+	case *dsmr.Tuple:
+		values := make([]string, len(v.Value))
+		for i, value := range v.Value {
+			values[i] = formatValue(value)
+		}
+		return strings.Join(values, ", ")
+	case *dsmr.RawObject:
+		return fmt.Sprintf("%s (%s)", v.Value, v.Err)
+	}
+
+	return fmt.Sprintf("%T", v)
+}
+
+func formatTime(t time.Time, err error) string {
+	if err != nil {
+		return "invalid time"
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,113 @@
+package dsmr
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// generalGloCiphering is the tag of an encrypted DLMS frame.
+	generalGloCiphering = 0xDB
+
+	// Security control bits of an authenticated and encrypted frame.
+	securityAuthenticated = 0x10
+	securityEncrypted     = 0x20
+
+	gcmTagSize = 12
+)
+
+var (
+	// ErrInvalidFrame is returned when an encrypted frame is malformed.
+	ErrInvalidFrame = errors.New("invalid encrypted frame")
+
+	// ErrAuthentication is returned when an encrypted frame cannot be
+	// authenticated, because of a wrong key or a corrupted frame.
+	ErrAuthentication = errors.New("cannot authenticate encrypted frame")
+)
+
+// EncryptedFrame is a DLMS general-glo-ciphering frame, as sent by Luxembourg
+// Smarty meters.
+type EncryptedFrame struct {
+	SystemTitle     []byte
+	SecurityControl byte
+	FrameCounter    uint32
+	Ciphertext      []byte
+	Tag             []byte
+}
+
+// ParseEncryptedFrame parses the fields of an encrypted frame.
+func ParseEncryptedFrame(b []byte) (*EncryptedFrame, error) {
+	if len(b) < 2 || b[0] != generalGloCiphering {
+		return nil, fmt.Errorf("%w: missing general-glo-ciphering tag", ErrInvalidFrame)
+	}
+
+	titleLen := int(b[1])
+	b = b[2:]
+
+	if len(b) < titleLen+3 || b[titleLen] != 0x82 {
+		return nil, fmt.Errorf("%w: missing length", ErrInvalidFrame)
+	}
+
+	f := &EncryptedFrame{SystemTitle: b[:titleLen]}
+
+	n := int(binary.BigEndian.Uint16(b[titleLen+1:]))
+	b = b[titleLen+3:]
+
+	// The length covers security control, frame counter, ciphertext and tag.
+	if n < 5+gcmTagSize || len(b) < n {
+		return nil, fmt.Errorf("%w: length %d out of range", ErrInvalidFrame, n)
+	}
+
+	f.SecurityControl = b[0]
+	f.FrameCounter = binary.BigEndian.Uint32(b[1:5])
+	f.Ciphertext = b[5 : n-gcmTagSize]
+	f.Tag = b[n-gcmTagSize : n]
+
+	return f, nil
+}
+
+// Decrypt authenticates and decrypts an encrypted frame with AES-128-GCM. The
+// key is the encryption key (GUEK) of the meter and aad the additional
+// authentication key (GAK), which is authenticated following the security
+// control byte of the frame.
+func Decrypt(frame []byte, key, aad []byte) ([]byte, error) {
+	f, err := ParseEncryptedFrame(frame)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.SecurityControl&(securityAuthenticated|securityEncrypted) != securityAuthenticated|securityEncrypted {
+		return nil, fmt.Errorf("%w: unsupported security control %#02x", ErrInvalidFrame, f.SecurityControl)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCMWithTagSize(block, gcmTagSize)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, 0, len(f.SystemTitle)+4)
+	iv = append(iv, f.SystemTitle...)
+	iv = binary.BigEndian.AppendUint32(iv, f.FrameCounter)
+
+	if len(iv) != gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: system title of %d bytes", ErrInvalidFrame, len(f.SystemTitle))
+	}
+
+	data := append([]byte{f.SecurityControl}, aad...)
+	sealed := append(append([]byte(nil), f.Ciphertext...), f.Tag...)
+
+	plaintext, err := gcm.Open(nil, iv, sealed, data)
+	if err != nil {
+		return nil, ErrAuthentication
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,126 @@
+package dsmr
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+// TestDecrypt decrypts test case 4 of the GCM specification, with its tag
+// truncated to 12 bytes and the first byte of its AAD as security control.
+func TestDecrypt(t *testing.T) {
+	key := unhex("feffe9928665731c6d6a8f9467308308")
+	aad := unhex("edfacedeadbeeffeedfacedeadbeefabaddad2")
+
+	frame := unhex("" +
+		"db08cafebabefacedbad" + // tag and system title
+		"82004d" + // length
+		"fe" + // security control
+		"decaf888" + // frame counter
+		"42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e" +
+		"21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091" +
+		"5bc94fbc3221a5db94fae95a") // tag
+
+	f, err := ParseEncryptedFrame(frame)
+	assert.NoError(t, err)
+	assert.Equal(t, unhex("cafebabefacedbad"), f.SystemTitle)
+	assert.Equal(t, uint32(0xdecaf888), f.FrameCounter)
+	assert.Equal(t, 60, len(f.Ciphertext))
+
+	plaintext, err := Decrypt(frame, key, aad)
+	assert.NoError(t, err)
+	assert.Equal(t, unhex(""+
+		"d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72"+
+		"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39"), plaintext)
+
+	frame[len(frame)-1] ^= 0xff
+	_, err = Decrypt(frame, key, aad)
+	assert.Equal(t, ErrAuthentication, err)
+}
+
+func TestDecryptInvalidFrame(t *testing.T) {
+	key := make([]byte, 16)
+
+	_, err := Decrypt([]byte("/header\r\n!\r\n"), key, nil)
+	assert.EqualError(t, err, "invalid encrypted frame: missing general-glo-ciphering tag")
+
+	_, err = Decrypt(unhex("db080102030405060708820010"), key, nil)
+	assert.EqualError(t, err, "invalid encrypted frame: length 16 out of range")
+
+	_, err = Decrypt(unhex("db0801020304050607088200111000000001"+strings.Repeat("00", 12)), key, nil)
+	assert.EqualError(t, err, "invalid encrypted frame: unsupported security control 0x10")
+}
+
+// encrypt wraps a telegram in an encrypted frame like Smarty meters send them.
+func encrypt(telegram string, key, aad []byte, counter uint32) []byte {
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCMWithTagSize(block, 12)
+
+	title := []byte("SAG12345")
+	iv := binary.BigEndian.AppendUint32(append([]byte(nil), title...), counter)
+	sealed := gcm.Seal(nil, iv, []byte(telegram), append([]byte{0x30}, aad...))
+
+	frame := append([]byte{0xDB, byte(len(title))}, title...)
+	frame = append(frame, 0x82)
+	frame = binary.BigEndian.AppendUint16(frame, uint16(5+len(sealed)))
+	frame = append(frame, 0x30)
+	frame = binary.BigEndian.AppendUint32(frame, counter)
+
+	return append(frame, sealed...)
+}
+
+func TestParseEncrypted(t *testing.T) {
+	key := unhex("000102030405060708090A0B0C0D0E0F")
+	aad := unhex("00112233445566778899AABBCCDDEEFF")
+
+	telegram, err := Parse(string(encrypt(telegramV42, key, aad, 1)), DecryptionKey(key, aad))
+	assert.NoError(t, err)
+	assert.Equal(t, "KFM5KAIFA-METER", telegram.Header.Value)
+
+	_, err = Parse(telegramV42, DecryptionKey(key[:8], aad))
+	assert.EqualError(t, err, "invalid decryption key of 8 bytes")
+}
+
+func TestReaderEncrypted(t *testing.T) {
+	key := unhex("000102030405060708090A0B0C0D0E0F")
+	aad := unhex("00112233445566778899AABBCCDDEEFF")
+
+	valid := encrypt(telegramV42, key, aad, 1)
+	corrupted := encrypt(telegramV42, key, aad, 2)
+	corrupted[len(corrupted)-1] ^= 0xff
+
+	stream := append([]byte("garbage"), valid...)
+	stream = append(stream, corrupted...)
+	stream = append(stream, valid[:20]...)
+
+	r := NewReader(strings.NewReader(string(stream)), DecryptionKey(key, aad))
+
+	telegram, err := r.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "6796", telegram.Footer.Value)
+
+	_, err = r.Next()
+	assert.True(t, errors.Is(err, ErrAuthentication))
+
+	var telegramErr *TelegramError
+	assert.True(t, errors.As(err, &telegramErr))
+
+	_, err = r.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
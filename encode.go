@@ -0,0 +1,145 @@
+package dsmr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Encode writes the telegram to w in the format it is sent by a meter. The
+// footer holds a freshly computed checksum of the encoded telegram.
+func Encode(w io.Writer, t *Telegram) error {
+	b, err := t.MarshalText()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// MarshalText encodes the telegram in the format it is sent by a meter. The
+// footer holds a freshly computed checksum of the encoded telegram.
+func (t *Telegram) MarshalText() ([]byte, error) {
+	if t.Header == nil {
+		return nil, errors.New("cannot encode telegram without header")
+	}
+
+	var b bytes.Buffer
+	b.WriteString("/" + t.Header.Value + "\r\n\r\n")
+
+	for _, obj := range t.Data {
+		if obj.OBIS != nil {
+			b.WriteString(obj.OBIS.Value)
+		}
+
+		if err := encodeValue(&b, obj.Value); err != nil {
+			return nil, fmt.Errorf("cannot encode %s: %w", obj.Key(), err)
+		}
+
+		b.WriteString("\r\n")
+	}
+
+	b.WriteByte('!')
+	b.WriteString(formatChecksum(Checksum(b.Bytes())))
+	b.WriteString("\r\n")
+
+	return b.Bytes(), nil
+}
+
+func encodeValue(b *bytes.Buffer, v Value) error {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("()")
+
+	case *String:
+		b.WriteString("(" + v.Value + ")")
+
+	case *OBIS:
+		b.WriteString("(" + v.Value + ")")
+
+	case *Number:
+		b.WriteString("(" + encodeNumber(v) + ")")
+
+	case *Timestamp:
+		b.WriteString("(" + encodeTimestamp(v) + ")")
+
+	case *Measurement:
+		b.WriteString("(" + encodeNumber(v.Value) + "*" + v.Unit.Value + ")")
+
+	case *EventLog:
+		b.WriteString("(" + encodeNumber(v.Count) + ")(" + v.OBIS.Value + ")")
+
+		for _, event := range v.Value {
+			if err := encodeValue(b, event); err != nil {
+				return err
+			}
+		}
+
+	case *PeakLog:
+		b.WriteString("(" + encodeNumber(v.Count) + ")(" + v.OBIS.Value + ")(" + v.PeakOBIS.Value + ")")
+
+		for _, peak := range v.Value {
+			if err := encodeValue(b, peak); err != nil {
+				return err
+			}
+		}
+
+	case *Peak:
+		b.WriteString("(" + encodeTimestamp(v.Month) + ")(" + encodeTimestamp(v.Timestamp) + ")")
+		return encodeValue(b, v.Value)
+
+	case *Event:
+		b.WriteString("(" + encodeTimestamp(v.Timestamp) + ")")
+		return encodeValue(b, v.Value)
+
+	case *LastCapture:
+		b.WriteString("(" + encodeTimestamp(v.Timestamp) + ")")
+		return encodeValue(b, v.Value)
+
+	case *LegacyLastCapture:
+		// The values between timestamp and OBIS are not kept while parsing, so we
+		// write the ones all known meters send.
+		b.WriteString("(" + v.Timestamp.Value + ")(00)(60)(1)(" + v.OBIS.Value + ")")
+		return encodeValue(b, v.Value)
+
+	case *Tuple:
+		for _, val := range v.Value {
+			if err := encodeValue(b, val); err != nil {
+				return err
+			}
+		}
+
+	case *RawObject:
+		b.WriteString(v.Value)
+
+	case *LegacyMeasurement:
+		b.WriteString("(" + v.Unit.Value + ")\r\n(" + encodeNumber(v.Value) + ")")
+
+	default:
+		return fmt.Errorf("unsupported value %T", v)
+	}
+
+	return nil
+}
+
+func encodeNumber(n *Number) string {
+	// Write back the digits of the meter, unless the value changed since.
+	if n.Text != "" {
+		if f, ok := new(big.Float).SetString(n.Text); ok && f.Cmp(n.Value) == 0 {
+			return n.Text
+		}
+	}
+
+	return n.Value.Text('f', -1)
+}
+
+func encodeTimestamp(t *Timestamp) string {
+	if t.DST {
+		return t.Value + "S"
+	}
+
+	return t.Value + "W"
+}
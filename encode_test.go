@@ -0,0 +1,155 @@
+package dsmr
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/alecthomas/repr"
+)
+
+func TestEncode(t *testing.T) {
+	telegram, err := Parse(telegramV42)
+	assert.NoError(t, err)
+
+	var b strings.Builder
+	err = Encode(&b, telegram)
+	assert.NoError(t, err)
+
+	// The digits of the meter are kept, so the telegram is encoded as sent.
+	assert.Equal(t, telegramV42, b.String())
+
+	telegram.find("1-0:1.8.1").Value.(*Measurement).Value.Value.SetFloat64(1.5)
+
+	b.Reset()
+	err = Encode(&b, telegram)
+	assert.NoError(t, err)
+
+	lines := strings.Split(b.String(), "\r\n")
+	assert.Equal(t, "1-0:1.8.1(1.5*kWh)", lines[5])
+}
+
+func TestEncodeLegacy(t *testing.T) {
+	telegram, err := Parse(telegramV22)
+	assert.NoError(t, err)
+
+	b, err := telegram.MarshalText()
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "0-1:24.3.0(161107190000)(00)(60)(1)(0-1:24.2.1)(m3)\r\n(00001.001)\r\n")
+}
+
+// TestEncodeRoundTrip verifies that parsing an encoded telegram results in the
+// telegram that was encoded, for randomly generated telegrams.
+func TestEncodeRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 500; i++ {
+		expected := randomTelegram(r)
+
+		b, err := expected.MarshalText()
+		assert.NoError(t, err)
+
+		telegram, err := Parse(string(b))
+		assert.NoError(t, err, "%s", b)
+
+		normalizeTelegram(telegram)
+		telegram.Footer = &Footer{}
+
+		assert.Equal(t,
+			repr.String(expected, repr.Indent("  ")),
+			repr.String(telegram, repr.Indent("  ")))
+	}
+}
+
+func randomTelegram(r *rand.Rand) *Telegram {
+	t := &Telegram{
+		Header: header(randomChars(r, "ABCDEFGHIJKLMNOPQRSTUVWXYZ", 3) + "5" + randomChars(r, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-\\", 12)),
+		Footer: &Footer{},
+	}
+
+	for i := r.Intn(40); i > 0; i-- {
+		t.Data = append(t.Data, obj(randomOBIS(r), randomValue(r)))
+	}
+
+	return t
+}
+
+func randomValue(r *rand.Rand) Value {
+	switch r.Intn(9) {
+	case 0:
+		return nil
+	case 1:
+		return str(randomChars(r, "ABCDEF", 1) + randomChars(r, "ABCDEF0123456789", r.Intn(40)))
+	case 2:
+		return ts(randomTimestamp(r), r.Intn(2) == 0)
+	case 3:
+		return randomMeasurement(r)
+	case 4:
+		var log []*Event
+		for i := r.Intn(5); i > 0; i-- {
+			log = append(log, event(ts(randomTimestamp(r), r.Intn(2) == 0), randomNumber(r)))
+		}
+		return events(fmt.Sprint(len(log)), randomOBIS(r), log...)
+	case 5:
+		var log []*Peak
+		for i := r.Intn(5); i > 0; i-- {
+			log = append(log, peak(ts(randomTimestamp(r), r.Intn(2) == 0), ts(randomTimestamp(r), r.Intn(2) == 0), randomMeasurement(r)))
+		}
+		return peaks(fmt.Sprint(len(log)), randomOBIS(r), randomOBIS(r), log...)
+	case 6:
+		values := []Attribute{str(randomChars(r, "ABCDEF", 1) + randomChars(r, "ABCDEF0123456789", r.Intn(10)))}
+		for i := r.Intn(4) + 1; i > 0; i-- {
+			switch r.Intn(4) {
+			case 0:
+				values = append(values, str(randomChars(r, "ABCDEF", 1)+randomChars(r, "ABCDEF0123456789", r.Intn(10))))
+			case 1:
+				values = append(values, ts(randomTimestamp(r), r.Intn(2) == 0))
+			case 2:
+				values = append(values, randomMeasurement(r))
+			default:
+				values = append(values, obis(randomOBIS(r)))
+			}
+		}
+		return tuple(values...)
+	case 7:
+		return lc(ts(randomTimestamp(r), r.Intn(2) == 0), randomMeasurement(r))
+	default:
+		return llc(str(randomTimestamp(r)), obis(randomOBIS(r)), lmm(randomNumber(r), randomUnit(r)))
+	}
+}
+
+func randomMeasurement(r *rand.Rand) *Measurement {
+	return mm(randomNumber(r), randomUnit(r))
+}
+
+func randomNumber(r *rand.Rand) string {
+	if r.Intn(2) == 0 {
+		return fmt.Sprint(r.Intn(100000))
+	}
+
+	return fmt.Sprintf("%d.%03d", r.Intn(100000), r.Intn(1000))
+}
+
+func randomUnit(r *rand.Rand) string {
+	units := []string{"kWh", "kW", "V", "A", "m3", "GJ", "s"}
+	return units[r.Intn(len(units))]
+}
+
+func randomTimestamp(r *rand.Rand) string {
+	return fmt.Sprintf("%02d%02d%02d%02d%02d%02d", r.Intn(100), r.Intn(12)+1, r.Intn(28)+1, r.Intn(24), r.Intn(60), r.Intn(60))
+}
+
+func randomOBIS(r *rand.Rand) string {
+	return fmt.Sprintf("%d-%d:%d.%d.%d", r.Intn(2), r.Intn(5), r.Intn(100), r.Intn(100), r.Intn(100))
+}
+
+func randomChars(r *rand.Rand, chars string, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = chars[r.Intn(len(chars))]
+	}
+
+	return string(b)
+}
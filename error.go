@@ -1,11 +1,122 @@
 package dsmr
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+)
 
 type Error interface {
 	error
 }
 
+// SyntaxError is returned when a telegram does not follow the DSMR grammar.
+type SyntaxError struct {
+	Pos  lexer.Position
+	Line string // Text of the line the error occurred on.
+	OBIS string // OBIS code of the object being parsed, if any.
+	Err  error
+}
+
+func (e *SyntaxError) Error() string { return e.Err.Error() }
+func (e *SyntaxError) Unwrap() error { return e.Err }
+
+// UnknownValueError is returned when the value of an object does not match any
+// of the known value types.
+type UnknownValueError struct {
+	Pos  lexer.Position
+	Line string // Text of the line the error occurred on.
+	OBIS string // OBIS code of the object being parsed.
+	Err  error
+}
+
+func (e *UnknownValueError) Error() string {
+	return fmt.Sprintf("%s: unknown value of %s: %s", e.Pos, e.OBIS, message(e.Err))
+}
+
+func (e *UnknownValueError) Unwrap() error { return e.Err }
+
+// TruncatedTelegramError is returned when a telegram ends halfway a line, like
+// when a read from a serial port got cut off.
+type TruncatedTelegramError struct {
+	Pos  lexer.Position
+	Line string // Text of the incomplete last line.
+	OBIS string // OBIS code of the object being parsed, if any.
+	Err  error
+}
+
+func (e *TruncatedTelegramError) Error() string {
+	return fmt.Sprintf("%s: truncated telegram: %s", e.Pos, message(e.Err))
+}
+
+func (e *TruncatedTelegramError) Unwrap() error { return e.Err }
+
+// MissingFooterError is returned when a telegram consists of complete lines,
+// but does not end with a footer.
+type MissingFooterError struct {
+	Pos  lexer.Position
+	Line string // Text of the last line.
+	OBIS string // OBIS code of the last object, if any.
+	Err  error
+}
+
+func (e *MissingFooterError) Error() string {
+	return fmt.Sprintf("%s: missing footer", e.Pos)
+}
+
+func (e *MissingFooterError) Unwrap() error { return e.Err }
+
+// ErrorList is returned by the [Lenient] option with the errors of all lines
+// that could not be parsed, and by [Telegram.Validate] with all problems found.
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// As finds the first error in the list that matches target, like [errors.As]
+// does for a single error.
+func (l ErrorList) As(target any) bool {
+	for _, err := range l {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Is reports whether any error in the list matches target, like [errors.Is]
+// does for a single error.
+func (l ErrorList) Is(target error) bool {
+	for _, err := range l {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// message returns the message of a parser error without its position.
+func message(err error) string {
+	if perr, ok := err.(participle.Error); ok {
+		return perr.Message()
+	}
+
+	return err.Error()
+}
+
 type ChecksumError struct {
 	Unexpected string
 	Expect     string
@@ -14,3 +125,84 @@ type ChecksumError struct {
 func (e *ChecksumError) Error() string {
 	return fmt.Sprintf("unexpected checksum \"%s\" (expected \"%s\")", e.Unexpected, e.Expect)
 }
+
+// DecodeError is returned when the value of an object cannot be decoded.
+type DecodeError struct {
+	OBIS string
+	Pos  lexer.Position
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s: cannot decode %s: %s", e.Pos, e.OBIS, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// MissingObjectError is returned by [Telegram.Validate] when a mandatory object
+// of a version is missing.
+type MissingObjectError struct {
+	OBIS    string
+	Version Version
+}
+
+func (e *MissingObjectError) Error() string {
+	return fmt.Sprintf("missing %s, mandatory in DSMR %s", e.OBIS, e.Version)
+}
+
+// UnitError is returned by [Telegram.Validate] when a value has an unexpected
+// unit.
+type UnitError struct {
+	OBIS   string
+	Pos    lexer.Position
+	Unit   string
+	Expect string
+}
+
+func (e *UnitError) Error() string {
+	return fmt.Sprintf("%s: unexpected unit \"%s\" of %s (expected \"%s\")", e.Pos, e.Unit, e.OBIS, e.Expect)
+}
+
+// InvalidUnmarshalError describes an invalid argument passed to [Unmarshal].
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "cannot unmarshal into nil"
+	}
+
+	return fmt.Sprintf("cannot unmarshal into non-pointer or non-struct %s", e.Type)
+}
+
+// TelegramError is returned by [Reader.Next] for a single telegram that could
+// not be read, unlike the errors of the underlying reader. Reading can go on
+// with the next telegram.
+type TelegramError struct {
+	Err error
+}
+
+func (e *TelegramError) Error() string { return e.Err.Error() }
+func (e *TelegramError) Unwrap() error { return e.Err }
+
+// ErrTelegramTooLarge is returned by [Reader.Next] when no footer was found
+// within the maximum size of a telegram.
+var ErrTelegramTooLarge = errors.New("telegram exceeds maximum size")
+
+// ErrUnknownVersion is returned by [Telegram.Validate] when there is no profile
+// to validate against.
+var ErrUnknownVersion = errors.New("unknown DSMR version")
+
+// ErrMissingChecksum and ErrUnexpectedChecksum are returned by
+// [Telegram.Validate] when the presence of the checksum in the footer does not
+// match the version.
+var (
+	ErrMissingChecksum    = errors.New("missing checksum")
+	ErrUnexpectedChecksum = errors.New("unexpected checksum in telegram without one")
+)
+
+// ErrUnsupportedTelegram is returned by [View.Parse] for a valid telegram that
+// does not fit a flat view, like one with line breaks within an object. Such
+// telegrams can be parsed with [Parse] instead.
+var ErrUnsupportedTelegram = errors.New("telegram does not fit a flat view")
@@ -0,0 +1,560 @@
+package dsmr
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// tokenKind is the kind of a token, matching the rules of the lexer.
+type tokenKind uint8
+
+const (
+	tokenInvalid tokenKind = iota
+	tokenOBIS
+	tokenTimestamp
+	tokenNumber
+	tokenChars
+	tokenPunct
+)
+
+type token struct {
+	kind       tokenKind
+	start, end int
+}
+
+// group is a parenthesised attribute of an object.
+type group struct {
+	line      int // Number of the line the attribute is on.
+	lineStart int // Offset of the start of that line.
+
+	first, last int // Range of the tokens of the attribute.
+	start, end  int // Offsets of the parentheses.
+}
+
+// fastParser is a hand-written parser that produces the same AST as the
+// grammar, for the telegrams that meters send in practice. It gives up on
+// anything out of the ordinary, like values it cannot tell apart without the
+// rules of the grammar, so these are left to the grammar instead.
+type fastParser struct {
+	str       string
+	line      int // Number of the current line.
+	lineStart int // Offset of the start of the current line.
+
+	tokens []token
+	groups []group
+}
+
+// parseFast parses a telegram with the hand-written parser. It reports false
+// when the telegram has to be parsed by the grammar.
+func parseFast(str string) (*Telegram, bool) {
+	p := &fastParser{str: str, line: 1}
+	return p.telegram()
+}
+
+func (p *fastParser) telegram() (*Telegram, bool) {
+	t := &Telegram{Pos: p.pos(0)}
+
+	header, footer, ok := p.scan(func() bool {
+		obj, ok := p.object()
+		t.Data = append(t.Data, obj)
+		return ok
+	})
+	if !ok {
+		return nil, false
+	}
+
+	t.Header = &Header{Pos: t.Pos, Value: p.str[1:header]}
+	t.Footer = &Footer{Pos: p.pos(footer), Value: p.str[footer+1 : p.lineEnd(footer)]}
+
+	return t, true
+}
+
+// scan reads the lines of a telegram, and calls object for each line that
+// starts an object. It returns the end of the header and the offset of the
+// footer, which is on the current line then.
+func (p *fastParser) scan(object func() bool) (header, footer int, ok bool) {
+	header = strings.Index(p.str, "\r\n")
+	if !strings.HasPrefix(p.str, "/") || header < 2 || !p.lex(1, header) {
+		return 0, 0, false
+	}
+	p.nextLine(header)
+
+	for {
+		off := p.lineStart
+
+		switch {
+		case off >= len(p.str):
+			return 0, 0, false
+
+		case strings.HasPrefix(p.str[off:], "\r\n"):
+			p.nextLine(off)
+
+		case p.str[off] == '!':
+			end := strings.Index(p.str[off:], "\r\n")
+			if end < 0 || !p.lex(off+1, off+end) {
+				return 0, 0, false
+			}
+
+			// Only empty lines may follow the footer.
+			for rest := p.str[off+end:]; rest != ""; rest = rest[2:] {
+				if !strings.HasPrefix(rest, "\r\n") {
+					return 0, 0, false
+				}
+			}
+
+			return header, off, true
+
+		default:
+			if !object() {
+				return 0, 0, false
+			}
+		}
+	}
+}
+
+func (p *fastParser) object() (*Object, bool) {
+	pos := p.pos(p.lineStart)
+	end, split, ok := p.readObject()
+	if !ok {
+		return nil, false
+	}
+
+	obj := &Object{
+		Pos:  pos,
+		OBIS: &OBIS{Pos: pos, Value: p.str[pos.Offset:end]},
+	}
+
+	obj.Value, ok = p.value(obj.OBIS.Value, split)
+	return obj, ok
+}
+
+// readObject reads the OBIS code and attributes of the object on the current
+// line, and moves on to the line after it. It returns the end of the OBIS code,
+// and the index of the first attribute on the next line.
+func (p *fastParser) readObject() (end, split int, ok bool) {
+	kind, end := lexToken(p.str, p.lineStart)
+	if kind != tokenOBIS {
+		return 0, 0, false
+	}
+
+	p.tokens = p.tokens[:0]
+	p.groups = p.groups[:0]
+
+	eol, ok := p.readGroups(end)
+	if !ok {
+		return 0, 0, false
+	}
+
+	// Values of legacy captures continue on the next line.
+	split = len(p.groups)
+	if strings.HasPrefix(p.str[eol:], "\r\n(") {
+		p.nextLine(eol)
+		if eol, ok = p.readGroups(p.lineStart); !ok {
+			return 0, 0, false
+		}
+	}
+	p.nextLine(eol)
+
+	return end, split, true
+}
+
+// readGroups reads the attributes from off up to the end of the line, and
+// returns the offset of the end of the line.
+func (p *fastParser) readGroups(off int) (int, bool) {
+	for {
+		if off >= len(p.str) || p.str[off] != '(' {
+			return 0, false
+		}
+
+		g := group{line: p.line, lineStart: p.lineStart, first: len(p.tokens), start: off}
+
+		i := off + 1
+		for i < len(p.str) && p.str[i] != ')' {
+			kind, end := lexToken(p.str, i)
+			if kind == tokenInvalid || p.str[i] == '(' {
+				return 0, false
+			}
+
+			p.tokens = append(p.tokens, token{kind: kind, start: i, end: end})
+			i = end
+		}
+
+		if i >= len(p.str) {
+			return 0, false
+		}
+
+		g.last, g.end = len(p.tokens), i
+		p.groups = append(p.groups, g)
+
+		off = i + 1
+		if strings.HasPrefix(p.str[off:], "\r\n") {
+			return off, true
+		}
+	}
+}
+
+// value turns the attributes of an object into a value, by making the same
+// choice as the grammar. The attributes from split on are on the next line.
+func (p *fastParser) value(obis string, split int) (Value, bool) {
+	gs := p.groups
+	n := len(gs)
+
+	// Only tuples of unknown objects hold empty attributes next to others.
+	for _, g := range gs {
+		if g.first == g.last && n > 1 {
+			if split != n || !allowsEmptyAttributes(obis) {
+				return nil, false
+			}
+			return p.tuple()
+		}
+	}
+
+	if legacy, ok := p.legacyLastCapture(); ok {
+		if split != n && split != n-1 {
+			return nil, false
+		}
+		return legacy, legacy != nil
+	}
+
+	if split != n {
+		return nil, false
+	}
+
+	if n == 1 {
+		toks := p.toks(gs[0])
+
+		switch {
+		case len(toks) == 0:
+			return nil, true
+		case p.isMeasurement(toks):
+			return p.measurement(gs[0]), true
+		case p.isTimestamp(toks):
+			return p.timestamp(gs[0]), true
+		case p.startsTimestamp(toks):
+			return nil, false
+		}
+
+		return p.string(gs[0], 0), true
+	}
+
+	// Event logs and peak logs both start with a count and OBIS code, and are
+	// told apart by the attribute that follows.
+	if p.isKind(gs[0], tokenNumber) && p.isKind(gs[1], tokenOBIS) {
+		if n > 2 && p.toks(gs[2])[0].kind == tokenOBIS {
+			return p.peakLog()
+		}
+
+		if n == 2 || p.toks(gs[2])[0].kind == tokenTimestamp {
+			return p.eventLog()
+		}
+	}
+
+	if n == 2 && p.isTimestamp(p.toks(gs[0])) && p.isMeasurement(p.toks(gs[1])) {
+		return &LastCapture{
+			Pos:       p.tokenPos(gs[0], 0),
+			Timestamp: p.timestamp(gs[0]),
+			Value:     p.measurement(gs[1]),
+		}, true
+	}
+
+	return p.tuple()
+}
+
+func (p *fastParser) peakLog() (Value, bool) {
+	gs := p.groups
+	if !p.isKind(gs[2], tokenOBIS) || (len(gs)-3)%3 != 0 {
+		return nil, false
+	}
+
+	v := &PeakLog{
+		Pos:      p.tokenPos(gs[0], 0),
+		Count:    p.number(gs[0], 0),
+		OBIS:     p.obis(gs[1]),
+		PeakOBIS: p.obis(gs[2]),
+	}
+
+	for i := 3; i < len(gs); i += 3 {
+		if !p.isTimestamp(p.toks(gs[i])) || !p.isTimestamp(p.toks(gs[i+1])) || !p.isMeasurement(p.toks(gs[i+2])) {
+			return nil, false
+		}
+
+		v.Value = append(v.Value, &Peak{
+			Pos:       p.groupPos(gs[i]),
+			Month:     p.timestamp(gs[i]),
+			Timestamp: p.timestamp(gs[i+1]),
+			Value:     p.measurement(gs[i+2]),
+		})
+	}
+
+	return v, true
+}
+
+func (p *fastParser) eventLog() (Value, bool) {
+	gs := p.groups
+	if len(gs)%2 != 0 {
+		return nil, false
+	}
+
+	v := &EventLog{
+		Pos:   p.tokenPos(gs[0], 0),
+		Count: p.number(gs[0], 0),
+		OBIS:  p.obis(gs[1]),
+	}
+
+	for i := 2; i < len(gs); i += 2 {
+		if !p.isTimestamp(p.toks(gs[i])) || !p.isMeasurement(p.toks(gs[i+1])) {
+			return nil, false
+		}
+
+		v.Value = append(v.Value, &Event{
+			Pos:       p.groupPos(gs[i]),
+			Timestamp: p.timestamp(gs[i]),
+			Value:     p.measurement(gs[i+1]),
+		})
+	}
+
+	return v, true
+}
+
+// legacyLastCapture reports true if the attributes start like a legacy last
+// capture, with a nil value if they do not continue like one.
+func (p *fastParser) legacyLastCapture() (Value, bool) {
+	gs := p.groups
+	n := len(gs)
+
+	// Any number of single values are ignored between timestamp and OBIS code.
+	i := 1
+	for i < n && gs[i].last-gs[i].first == 1 && p.toks(gs[i])[0].kind != tokenOBIS {
+		i++
+	}
+
+	if i == 1 || i+2 >= n || !p.isKind(gs[i], tokenOBIS) || !p.isKind(gs[i+2], tokenNumber) {
+		return nil, false
+	}
+
+	if i+2 != n-1 {
+		return nil, true
+	}
+
+	return &LegacyLastCapture{
+		Pos:       p.tokenPos(gs[0], 0),
+		Timestamp: p.string(gs[0], 0),
+		OBIS:      p.obis(gs[i]),
+		Value: &LegacyMeasurement{
+			Pos:   p.tokenPos(gs[i+1], 0),
+			Unit:  p.string(gs[i+1], 0),
+			Value: p.number(gs[i+2], 0),
+		},
+	}, true
+}
+
+func (p *fastParser) tuple() (Value, bool) {
+	v := &Tuple{Pos: p.startPos(p.groups[0])}
+
+	for _, g := range p.groups {
+		toks := p.toks(g)
+
+		switch {
+		case len(toks) == 0:
+			v.Value = append(v.Value, &String{Pos: p.startPos(g)})
+		case p.isMeasurement(toks):
+			v.Value = append(v.Value, p.measurement(g))
+		case p.isTimestamp(toks):
+			v.Value = append(v.Value, p.timestamp(g))
+		case p.isKind(g, tokenOBIS):
+			v.Value = append(v.Value, p.obis(g))
+		case p.startsTimestamp(toks), toks[0].kind == tokenOBIS:
+			return nil, false
+		default:
+			v.Value = append(v.Value, p.string(g, 0))
+		}
+	}
+
+	return v, true
+}
+
+func (p *fastParser) toks(g group) []token { return p.tokens[g.first:g.last] }
+
+// isKind reports whether the attribute is a single token of the kind.
+func (p *fastParser) isKind(g group, kind tokenKind) bool {
+	return g.last-g.first == 1 && p.tokens[g.first].kind == kind
+}
+
+func (p *fastParser) isMeasurement(toks []token) bool {
+	return len(toks) > 2 && toks[0].kind == tokenNumber && p.text(toks[1]) == "*"
+}
+
+func (p *fastParser) isTimestamp(toks []token) bool {
+	return len(toks) == 2 && p.startsTimestamp(toks)
+}
+
+func (p *fastParser) startsTimestamp(toks []token) bool {
+	if len(toks) < 2 || toks[0].kind != tokenTimestamp {
+		return false
+	}
+
+	flag := p.text(toks[1])
+	return flag == "S" || flag == "W"
+}
+
+func (p *fastParser) measurement(g group) *Measurement {
+	return &Measurement{
+		Pos:   p.tokenPos(g, 0),
+		Value: p.number(g, 0),
+		Unit:  p.string(g, 2),
+	}
+}
+
+func (p *fastParser) timestamp(g group) *Timestamp {
+	toks := p.toks(g)
+	return &Timestamp{
+		Pos:   p.tokenPos(g, 0),
+		Value: p.text(toks[0]),
+		DST:   p.text(toks[1]) == "S",
+	}
+}
+
+func (p *fastParser) obis(g group) *OBIS {
+	return &OBIS{Pos: p.tokenPos(g, 0), Value: p.text(p.tokens[g.first])}
+}
+
+func (p *fastParser) number(g group, i int) *Number {
+	text := p.text(p.tokens[g.first+i])
+
+	// Number tokens are always valid numbers.
+	f, _ := new(big.Float).SetString(text)
+
+	return &Number{Pos: p.tokenPos(g, i), Value: f, Text: text}
+}
+
+// string returns the text of the attribute from its i-th token on.
+func (p *fastParser) string(g group, i int) *String {
+	return &String{
+		Pos:   p.tokenPos(g, i),
+		Value: p.str[p.tokens[g.first+i].start:p.tokens[g.last-1].end],
+	}
+}
+
+func (p *fastParser) text(t token) string { return p.str[t.start:t.end] }
+
+// groupPos returns the position of the opening parenthesis of the attribute.
+func (p *fastParser) groupPos(g group) lexer.Position {
+	return lexer.Position{Offset: g.start, Line: g.line, Column: g.start - g.lineStart + 1}
+}
+
+// startPos returns the position of the first token of the attribute, or of
+// its closing parenthesis when it is empty.
+func (p *fastParser) startPos(g group) lexer.Position {
+	if g.first == g.last {
+		return lexer.Position{Offset: g.end, Line: g.line, Column: g.end - g.lineStart + 1}
+	}
+	return p.tokenPos(g, 0)
+}
+
+func (p *fastParser) tokenPos(g group, i int) lexer.Position {
+	off := p.tokens[g.first+i].start
+	return lexer.Position{Offset: off, Line: g.line, Column: off - g.lineStart + 1}
+}
+
+func (p *fastParser) pos(off int) lexer.Position {
+	return lexer.Position{Offset: off, Line: p.line, Column: off - p.lineStart + 1}
+}
+
+// lineEnd returns the offset of the line break of the line at off.
+func (p *fastParser) lineEnd(off int) int {
+	return off + strings.Index(p.str[off:], "\r\n")
+}
+
+// nextLine moves to the line after the line break at eol.
+func (p *fastParser) nextLine(eol int) {
+	p.line++
+	p.lineStart = eol + 2
+}
+
+// lex reports whether the text from start to end consists of valid tokens.
+func (p *fastParser) lex(start, end int) bool {
+	for i := start; i < end; {
+		kind, next := lexToken(p.str[:end], i)
+		if kind == tokenInvalid {
+			return false
+		}
+		i = next
+	}
+
+	return true
+}
+
+// lexToken returns the kind and end of the token at offset i, following the
+// rules of the lexer in the same order.
+func lexToken(s string, i int) (tokenKind, int) {
+	if end := lexOBIS(s, i); end > i {
+		return tokenOBIS, end
+	}
+
+	d := digits(s, i)
+	if d >= 12 {
+		return tokenTimestamp, i + 12
+	}
+
+	if i+d+1 < len(s) && s[i+d] == '.' && isDigit(s[i+d+1]) {
+		return tokenNumber, i + d + 1 + digits(s, i+d+1)
+	}
+	if d > 0 {
+		return tokenNumber, i + d
+	}
+
+	end := i
+	for end < len(s) && (isDigit(s[end]) || 'a' <= s[end] && s[end] <= 'z' || 'A' <= s[end] && s[end] <= 'Z') {
+		end++
+	}
+	if end > i {
+		return tokenChars, end
+	}
+
+	if i < len(s) && strings.IndexByte(`-_!*.\/()`, s[i]) >= 0 {
+		return tokenPunct, i + 1
+	}
+
+	return tokenInvalid, i
+}
+
+// lexOBIS returns the end of the OBIS code at offset i, or i if there is none.
+func lexOBIS(s string, i int) int {
+	if i+4 >= len(s) || !isDigit(s[i]) || s[i+1] != '-' || !isDigit(s[i+2]) || s[i+3] != ':' {
+		return i
+	}
+
+	j := i + 4
+	for k := 0; k < 3; k++ {
+		d := digits(s, j)
+		if d == 0 {
+			return i
+		}
+		if d > 2 {
+			d = 2
+		}
+
+		j += d
+		if k < 2 {
+			if j >= len(s) || s[j] != '.' {
+				return i
+			}
+			j++
+		}
+	}
+
+	return j
+}
+
+func digits(s string, i int) int {
+	n := 0
+	for i+n < len(s) && isDigit(s[i+n]) {
+		n++
+	}
+
+	return n
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
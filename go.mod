@@ -6,7 +6,6 @@ require (
 	github.com/alecthomas/assert/v2 v2.11.0
 	github.com/alecthomas/participle/v2 v2.1.4
 	github.com/alecthomas/repr v0.4.0
-	github.com/snksoft/crc v1.1.0
 )
 
 require github.com/hexops/gotextdiff v1.0.3 // indirect
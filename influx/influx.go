@@ -0,0 +1,348 @@
+// Package influx encodes telegrams in the InfluxDB line protocol and writes
+// them to InfluxDB. Each telegram becomes a line per group of objects, like
+// the electricity meter, its phases and its MBus devices, timestamped with the
+// time the meter sent the telegram:
+//
+//	w := influx.NewWriter("http://localhost:8086", "home", "energy", token)
+//	err := w.Write(ctx, telegram)
+package influx
+
+import (
+	"bufio"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/robinvdvleuten/dsmr"
+)
+
+// field is a field of a register, which holds the value of a single OBIS code.
+type field struct {
+	measurement string
+	phase       string // Phase of the measurement, if any.
+	key         string
+	unit        dsmr.Unit // Unit the value is converted into, unknown for integers.
+}
+
+var registers = map[string]field{
+	"1-0:1.8.0":   {"electricity", "", "delivered_kwh", dsmr.UnitKWh},
+	"1-0:1.8.1":   {"electricity", "", "delivered_tariff1_kwh", dsmr.UnitKWh},
+	"1-0:1.8.2":   {"electricity", "", "delivered_tariff2_kwh", dsmr.UnitKWh},
+	"1-0:2.8.0":   {"electricity", "", "returned_kwh", dsmr.UnitKWh},
+	"1-0:2.8.1":   {"electricity", "", "returned_tariff1_kwh", dsmr.UnitKWh},
+	"1-0:2.8.2":   {"electricity", "", "returned_tariff2_kwh", dsmr.UnitKWh},
+	"0-0:96.14.0": {"electricity", "", "tariff", dsmr.UnitUnknown},
+	"1-0:1.7.0":   {"electricity", "", "power_delivered_kw", dsmr.UnitKW},
+	"1-0:2.7.0":   {"electricity", "", "power_returned_kw", dsmr.UnitKW},
+	"0-0:96.7.21": {"electricity", "", "power_failures", dsmr.UnitUnknown},
+	"0-0:96.7.9":  {"electricity", "", "long_power_failures", dsmr.UnitUnknown},
+	"1-0:32.7.0":  {"phases", "l1", "voltage_v", dsmr.UnitV},
+	"1-0:52.7.0":  {"phases", "l2", "voltage_v", dsmr.UnitV},
+	"1-0:72.7.0":  {"phases", "l3", "voltage_v", dsmr.UnitV},
+	"1-0:31.7.0":  {"phases", "l1", "current_a", dsmr.UnitA},
+	"1-0:51.7.0":  {"phases", "l2", "current_a", dsmr.UnitA},
+	"1-0:71.7.0":  {"phases", "l3", "current_a", dsmr.UnitA},
+	"1-0:21.7.0":  {"phases", "l1", "power_delivered_kw", dsmr.UnitKW},
+	"1-0:41.7.0":  {"phases", "l2", "power_delivered_kw", dsmr.UnitKW},
+	"1-0:61.7.0":  {"phases", "l3", "power_delivered_kw", dsmr.UnitKW},
+	"1-0:22.7.0":  {"phases", "l1", "power_returned_kw", dsmr.UnitKW},
+	"1-0:42.7.0":  {"phases", "l2", "power_returned_kw", dsmr.UnitKW},
+	"1-0:62.7.0":  {"phases", "l3", "power_returned_kw", dsmr.UnitKW},
+}
+
+// devices are the fields of MBus devices by their type.
+var devices = map[dsmr.DeviceType]field{
+	dsmr.DeviceGas:   {"gas", "", "delivered_m3", dsmr.UnitM3},
+	dsmr.DeviceWater: {"water", "", "delivered_m3", dsmr.UnitM3},
+	dsmr.DeviceHeat:  {"heat", "", "delivered_gj", dsmr.UnitGJ},
+}
+
+// point is a line of the line protocol.
+type point struct {
+	measurement string
+	tags        map[string]string
+	fields      map[string]string // Formatted values by their key.
+}
+
+// Encode writes the objects of a telegram to w in the line protocol, with the
+// given tags on every line. There is a line for the electricity meter, one
+// for each phase and one for each gas, water or heat meter connected over
+// MBus. Devices without a type that measure in m3 are taken as gas meters,
+// like those of DSMR 2.2 meters. Values are converted into the units in their field names, without
+// losing the precision of the meter.
+//
+// Lines are timestamped in nanoseconds with 0-0:1.0.0. They have no timestamp
+// when the telegram has none, like those of DSMR 2.2, so that the server uses
+// the time they are written. Objects that cannot be converted, like those
+// with an unexpected unit, are left out.
+func Encode(w io.Writer, t *dsmr.Telegram, tags map[string]string) error {
+	var points []*point
+	groups := map[string]*point{}
+
+	group := func(measurement string, extra map[string]string) *point {
+		key := measurement + formatTags(extra)
+		if p, ok := groups[key]; ok {
+			return p
+		}
+
+		p := &point{measurement: measurement, tags: map[string]string{}, fields: map[string]string{}}
+		for k, v := range tags {
+			p.tags[k] = v
+		}
+		for k, v := range extra {
+			p.tags[k] = v
+		}
+
+		groups[key] = p
+		points = append(points, p)
+		return p
+	}
+
+	for _, obj := range t.Data {
+		f, ok := registers[obj.Key()]
+		if !ok {
+			continue
+		}
+
+		value, ok := format(obj.Value, f.unit)
+		if !ok {
+			continue
+		}
+
+		var extra map[string]string
+		if f.phase != "" {
+			extra = map[string]string{"phase": f.phase}
+		}
+
+		group(f.measurement, extra).fields[f.key] = value
+	}
+
+	// Objects of devices that cannot be decoded are left out, like the others.
+	mbus, _ := t.MBusDevices()
+	for _, device := range mbus {
+		f, ok := devices[deviceType(device)]
+		if !ok {
+			continue
+		}
+
+		if value, ok := latest(device, f.unit); ok {
+			group(f.measurement, map[string]string{"channel": strconv.Itoa(device.Channel)}).fields[f.key] = value
+		}
+	}
+
+	ts := timestamp(t)
+
+	bw := bufio.NewWriter(w)
+	for _, p := range points {
+		writePoint(bw, p, ts)
+	}
+
+	return bw.Flush()
+}
+
+// timestamp returns the time the meter sent a telegram in nanoseconds, or an
+// empty string if it has none.
+func timestamp(t *dsmr.Telegram) string {
+	obj := find(t, "0-0:1.0.0")
+	if obj == nil {
+		return ""
+	}
+
+	ts, ok := obj.Value.(*dsmr.Timestamp)
+	if !ok {
+		return ""
+	}
+
+	tm, err := ts.Time()
+	if err != nil {
+		return ""
+	}
+
+	return strconv.FormatInt(tm.UnixNano(), 10)
+}
+
+// format returns the value of a measurement in unit, or of a plain number as
+// an integer.
+func format(v dsmr.Value, unit dsmr.Unit) (string, bool) {
+	switch v := v.(type) {
+	case *dsmr.Measurement:
+		value, err := v.In(unit)
+		if err != nil {
+			return "", false
+		}
+		return formatFloat(value), true
+	case *dsmr.LegacyMeasurement:
+		value, err := v.In(unit)
+		if err != nil {
+			return "", false
+		}
+		return formatFloat(value), true
+	case *dsmr.Number:
+		if unit != dsmr.UnitUnknown || !v.Value.IsInt() {
+			return "", false
+		}
+		return v.Value.Text('f', 0) + "i", true
+	case *dsmr.String:
+		if unit != dsmr.UnitUnknown {
+			return "", false
+		}
+		i, err := strconv.ParseInt(v.Value, 10, 64)
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatInt(i, 10) + "i", true
+	}
+
+	return "", false
+}
+
+// deviceType returns the type of an MBus device. Meters of DSMR 2.2 may not
+// send the type of their device, which is a gas meter then, so devices without
+// a type that measure in m3 are taken as gas meters.
+func deviceType(device *dsmr.MBusDevice) dsmr.DeviceType {
+	if device.Type != dsmr.DeviceOther || device.Unit != dsmr.UnitM3.String() {
+		return device.Type
+	}
+
+	for _, obj := range device.Objects {
+		if code, err := obj.OBIS.Code(); err == nil && code.Indicator == 24 && code.Mode == 1 && code.Quantity == 0 {
+			return device.Type
+		}
+	}
+
+	return dsmr.DeviceGas
+}
+
+// latest returns the value of the latest capture of an MBus device in unit.
+func latest(device *dsmr.MBusDevice, unit dsmr.Unit) (string, bool) {
+	if device.Timestamp.IsZero() {
+		return "", false
+	}
+
+	for _, obj := range device.Objects {
+		var (
+			value *big.Float
+			err   error
+		)
+
+		switch v := obj.Value.(type) {
+		case *dsmr.LastCapture:
+			if tm, terr := v.Timestamp.Time(); terr != nil || !tm.Equal(device.Timestamp) {
+				continue
+			}
+			value, err = v.Value.In(unit)
+		case *dsmr.LegacyLastCapture:
+			if tm, terr := v.Time(); terr != nil || !tm.Equal(device.Timestamp) {
+				continue
+			}
+			value, err = v.Value.In(unit)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return "", false
+		}
+
+		return formatFloat(value), true
+	}
+
+	return "", false
+}
+
+// formatFloat formats a value with the shortest decimal representation that
+// reads back as the same value, so that no digits of the meter are lost.
+func formatFloat(f *big.Float) string {
+	return f.Text('f', -1)
+}
+
+// writePoint writes a point with its tags and fields ordered by key. Points
+// without fields are left out, as the line protocol requires at least one.
+func writePoint(w *bufio.Writer, p *point, timestamp string) {
+	if len(p.fields) == 0 {
+		return
+	}
+
+	w.WriteString(escape(p.measurement, ", "))
+
+	keys := make([]string, 0, len(p.tags))
+	for k, v := range p.tags {
+		// Empty tag values are not allowed.
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		w.WriteByte(',')
+		w.WriteString(escape(k, ",= "))
+		w.WriteByte('=')
+		w.WriteString(escape(p.tags[k], ",= "))
+	}
+
+	keys = keys[:0]
+	for k := range p.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		if i == 0 {
+			w.WriteByte(' ')
+		} else {
+			w.WriteByte(',')
+		}
+		w.WriteString(escape(k, ",= "))
+		w.WriteByte('=')
+		w.WriteString(p.fields[k])
+	}
+
+	if timestamp != "" {
+		w.WriteByte(' ')
+		w.WriteString(timestamp)
+	}
+
+	w.WriteByte('\n')
+}
+
+// escape escapes the given characters with a backslash.
+func escape(s, chars string) string {
+	if !strings.ContainsAny(s, chars) {
+		return s
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(chars, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// formatTags formats tags ordered by key, to tell groups of points apart.
+func formatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// find returns the first object with the given OBIS code.
+func find(t *dsmr.Telegram, key string) *dsmr.Object {
+	for _, obj := range t.Data {
+		if obj.Key() == key {
+			return obj
+		}
+	}
+
+	return nil
+}
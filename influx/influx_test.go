@@ -0,0 +1,180 @@
+package influx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/robinvdvleuten/dsmr"
+)
+
+const telegram = "" +
+	"/KFM5KAIFA-METER\r\n" +
+	"\r\n" +
+	"1-3:0.2.8(42)\r\n" +
+	"0-0:1.0.0(161113205757W)\r\n" +
+	"1-0:1.8.1(001581.123*kWh)\r\n" +
+	"1-0:1.8.2(001435.706*kWh)\r\n" +
+	"1-0:2.8.1(000000.000*kWh)\r\n" +
+	"1-0:2.8.2(000000.000*kWh)\r\n" +
+	"0-0:96.14.0(0002)\r\n" +
+	"1-0:1.7.0(02.027*kW)\r\n" +
+	"1-0:2.7.0(00.000*kW)\r\n" +
+	"0-0:96.7.21(00015)\r\n" +
+	"1-0:32.7.0(230.1*V)\r\n" +
+	"1-0:31.7.0(000*A)\r\n" +
+	"1-0:51.7.0(006*A)\r\n" +
+	"1-0:21.7.0(170*W)\r\n" +
+	"1-0:41.7.0(00.123*kWh)\r\n" +
+	"0-1:24.1.0(003)\r\n" +
+	"0-1:24.2.1(161129190000W)(00980.001*m3)\r\n" +
+	"0-1:24.2.1(161129200000W)(00981.443*m3)\r\n" +
+	"0-2:24.1.0(004)\r\n" +
+	"0-2:24.2.1(161129200000W)(00123.456*GJ)\r\n" +
+	"0-3:24.1.0(007)\r\n" +
+	"0-3:24.2.1(161129200000W)(00012.500*m3)\r\n" +
+	"0-4:24.1.0(004)\r\n" +
+	"0-4:24.2.1(161129200000W)(1234567*MWh)\r\n" +
+	"!\r\n"
+
+const expected = "" +
+	"electricity,host=pi delivered_tariff1_kwh=1581.123,delivered_tariff2_kwh=1435.706,power_delivered_kw=2.027,power_failures=15i,power_returned_kw=0,returned_tariff1_kwh=0,returned_tariff2_kwh=0,tariff=2i 1479067077000000000\n" +
+	"phases,host=pi,phase=l1 current_a=0,power_delivered_kw=0.17,voltage_v=230.1 1479067077000000000\n" +
+	"phases,host=pi,phase=l2 current_a=6 1479067077000000000\n" +
+	"gas,channel=1,host=pi delivered_m3=981.443 1479067077000000000\n" +
+	"heat,channel=2,host=pi delivered_gj=123.456 1479067077000000000\n" +
+	"water,channel=3,host=pi delivered_m3=12.5 1479067077000000000\n"
+
+func parse(t *testing.T, raw string) *dsmr.Telegram {
+	t.Helper()
+
+	telegram, err := dsmr.Parse(raw, dsmr.VerifyChecksum(false))
+	assert.NoError(t, err)
+
+	return telegram
+}
+
+func TestEncode(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, Encode(&b, parse(t, telegram), map[string]string{"host": "pi", "empty": ""}))
+	assert.Equal(t, expected, b.String())
+}
+
+func TestEncodeUndecodableDevice(t *testing.T) {
+	raw := strings.Replace(telegram, "0-2:24.1.0(004)\r\n", "0-2:24.1.0(heat)\r\n", 1)
+
+	var b strings.Builder
+	assert.NoError(t, Encode(&b, parse(t, raw), map[string]string{"host": "pi"}))
+	assert.Equal(t, strings.Replace(expected, "heat,channel=2,host=pi delivered_gj=123.456 1479067077000000000\n", "", 1), b.String())
+}
+
+func TestEncodeWithoutTimestamp(t *testing.T) {
+	raw := "" +
+		"/ISk5\\2MT382-1004\r\n" +
+		"\r\n" +
+		"1-0:1.8.1(00001.001*kWh)\r\n" +
+		"0-1:24.3.0(120517020000)(08)(60)(1)(0-1:24.2.1)(m3)\r\n" +
+		"(00124.477)\r\n" +
+		"!\r\n"
+
+	var b strings.Builder
+	assert.NoError(t, Encode(&b, parse(t, raw), map[string]string{"meter name": "a,b=c"}))
+	assert.Equal(t, ""+
+		"electricity,meter\\ name=a\\,b\\=c delivered_tariff1_kwh=1.001\n"+
+		"gas,channel=1,meter\\ name=a\\,b\\=c delivered_m3=124.477\n", b.String())
+}
+
+func TestWriter(t *testing.T) {
+	var (
+		req  *http.Request
+		body string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		req, body = r, string(data)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	w := NewWriter(srv.URL+"/", "home", "energy", "secret")
+	w.Tags = map[string]string{"host": "pi"}
+
+	tg := parse(t, telegram)
+	assert.NoError(t, w.Write(context.Background(), tg, tg))
+
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "/api/v2/write", req.URL.Path)
+	assert.Equal(t, "home", req.URL.Query().Get("org"))
+	assert.Equal(t, "energy", req.URL.Query().Get("bucket"))
+	assert.Equal(t, "ns", req.URL.Query().Get("precision"))
+	assert.Equal(t, "Token secret", req.Header.Get("Authorization"))
+	assert.Equal(t, "text/plain; charset=utf-8", req.Header.Get("Content-Type"))
+	assert.Equal(t, expected+expected, body)
+}
+
+func TestWriterEmpty(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	}))
+	defer srv.Close()
+
+	w := NewWriter(srv.URL, "home", "energy", "")
+	assert.NoError(t, w.Write(context.Background(), parse(t, "/header\r\n\r\n0-0:96.1.1(1234)\r\n!\r\n")))
+}
+
+func TestWriterWithoutTimestamp(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	untimed := parse(t, "/ISk5\\2MT382-1004\r\n\r\n1-0:1.8.1(00001.001*kWh)\r\n!\r\n")
+	empty := parse(t, "/header\r\n\r\n0-0:96.1.1(1234)\r\n!\r\n")
+
+	w := NewWriter(srv.URL, "home", "energy", "")
+	assert.NoError(t, w.Write(context.Background(), untimed, empty, parse(t, telegram)))
+	assert.Equal(t, 1, requests)
+
+	err := w.Write(context.Background(), untimed, parse(t, telegram), untimed)
+	assert.Equal(t, ErrMissingTimestamp, err)
+	assert.Equal(t, 1, requests)
+}
+
+func TestWriterError(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"JSON", `{"code":"unauthorized","message":"unauthorized access"}`, "influx: write failed: 401 Unauthorized: unauthorized access"},
+		{"Text", "denied\n", "influx: write failed: 401 Unauthorized: denied"},
+		{"Empty", "", "influx: write failed: 401 Unauthorized"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = io.WriteString(w, test.body)
+			}))
+			defer srv.Close()
+
+			err := NewWriter(srv.URL, "home", "energy", "wrong").Write(context.Background(), parse(t, telegram))
+
+			var writeErr *WriteError
+			assert.True(t, errors.As(err, &writeErr))
+			assert.Equal(t, http.StatusUnauthorized, writeErr.StatusCode)
+			assert.EqualError(t, err, test.expected)
+		})
+	}
+}
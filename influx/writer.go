@@ -0,0 +1,132 @@
+package influx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/robinvdvleuten/dsmr"
+)
+
+// ErrMissingTimestamp is returned by [Writer.Write] for a batch with more than
+// one telegram without a timestamp. The server would give their lines the same
+// time, so that all but the last would be lost.
+var ErrMissingTimestamp = errors.New("influx: more than one telegram without timestamp in batch")
+
+// maxErrorBody limits the part of the body of a failed write that is read.
+const maxErrorBody = 4 << 10
+
+// WriteError is returned when the server refuses a write.
+type WriteError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *WriteError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("influx: write failed: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	return fmt.Sprintf("influx: write failed: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Writer writes telegrams to a bucket of InfluxDB, through the /api/v2/write
+// endpoint of its HTTP API.
+type Writer struct {
+	URL    string // Base URL of the server, like "http://localhost:8086".
+	Org    string
+	Bucket string
+	Token  string
+
+	// Tags are added to every line written.
+	Tags map[string]string
+
+	// Client sends the requests, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// NewWriter returns a writer writing to a bucket of an organization on the
+// server at serverURL, authenticated with an API token.
+func NewWriter(serverURL, org, bucket, token string) *Writer {
+	return &Writer{URL: serverURL, Org: org, Bucket: bucket, Token: token}
+}
+
+// Write writes a batch of telegrams in a single request. Nothing is sent when
+// the telegrams hold no values. Telegrams without a timestamp, like those of
+// DSMR 2.2, are timestamped by the server, so at most one of them can be
+// written per batch; see [ErrMissingTimestamp].
+func (w *Writer) Write(ctx context.Context, telegrams ...*dsmr.Telegram) error {
+	var (
+		body    bytes.Buffer
+		untimed int // Number of telegrams with lines but without a timestamp.
+	)
+
+	for _, t := range telegrams {
+		n := body.Len()
+		if err := Encode(&body, t, w.Tags); err != nil {
+			return err
+		}
+
+		if body.Len() > n && timestamp(t) == "" {
+			untimed++
+		}
+	}
+
+	if untimed > 1 {
+		return ErrMissingTimestamp
+	}
+
+	if body.Len() == 0 {
+		return nil
+	}
+
+	query := url.Values{}
+	query.Set("org", w.Org)
+	query.Set("bucket", w.Bucket)
+	query.Set("precision", "ns")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(w.URL, "/")+"/api/v2/write?"+query.Encode(), &body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.Token != "" {
+		req.Header.Set("Authorization", "Token "+w.Token)
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	// The server explains errors in a JSON object, but proxies may not.
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	message := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+		message = apiErr.Message
+	}
+
+	return &WriteError{StatusCode: resp.StatusCode, Message: message}
+}
@@ -0,0 +1,378 @@
+package dsmr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// valueTypes holds the types of values by their name in JSON.
+var valueTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, v := range []Value{
+		&PeakLog{}, &EventLog{}, &LastCapture{}, &LegacyLastCapture{}, &Tuple{}, &RawObject{},
+		&Measurement{}, &LegacyMeasurement{}, &Timestamp{}, &Number{}, &OBIS{}, &String{},
+	} {
+		t := reflect.TypeOf(v).Elem()
+		valueTypes[jsonName(t.Name())] = t
+	}
+}
+
+var (
+	positionType = reflect.TypeOf(lexer.Position{})
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+
+	bigFloatPointerType = reflect.PointerTo(bigFloatType)
+)
+
+// jsonPosition is the JSON representation of a position.
+type jsonPosition struct {
+	Filename string `json:"filename,omitempty"`
+	Offset   int    `json:"offset"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+// JSONOptions configures the encoding of telegrams as JSON.
+type JSONOptions struct {
+	Positions bool // Include the positions of nodes in the telegram.
+}
+
+// Marshal encodes a telegram as JSON. Every node becomes an object with its
+// fields, and values have a "type" field with the name of their type, like
+// "measurement" or "lastCapture". Numbers are encoded as strings with the
+// digits sent by the meter.
+func (o JSONOptions) Marshal(t *Telegram) ([]byte, error) {
+	e := &jsonEncoder{positions: o.Positions}
+	if err := e.node(reflect.ValueOf(t), false); err != nil {
+		return nil, err
+	}
+
+	return e.Bytes(), nil
+}
+
+// MarshalValue encodes a single value as JSON, like the values of objects in
+// [JSONOptions.Marshal].
+func (o JSONOptions) MarshalValue(v Value) ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+
+	e := &jsonEncoder{positions: o.Positions}
+	if err := e.node(reflect.ValueOf(v), true); err != nil {
+		return nil, err
+	}
+
+	return e.Bytes(), nil
+}
+
+// MarshalJSON encodes the telegram as JSON with positions, see
+// [JSONOptions.Marshal]. Positions that are zero, like of telegrams that were
+// not parsed, are left out.
+func (t *Telegram) MarshalJSON() ([]byte, error) {
+	return JSONOptions{Positions: true}.Marshal(t)
+}
+
+// UnmarshalJSON decodes a telegram encoded by [Telegram.MarshalJSON]. Numbers
+// may also be JSON numbers, and positions may be left out.
+func (t *Telegram) UnmarshalJSON(data []byte) error {
+	return unmarshalNode(data, reflect.ValueOf(t).Elem())
+}
+
+// MarshalJSON encodes the object as JSON, like the objects in the data of
+// [Telegram.MarshalJSON].
+func (o *Object) MarshalJSON() ([]byte, error) {
+	e := &jsonEncoder{positions: true}
+	if err := e.node(reflect.ValueOf(o), false); err != nil {
+		return nil, err
+	}
+
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON decodes an object encoded by [Object.MarshalJSON].
+func (o *Object) UnmarshalJSON(data []byte) error {
+	return unmarshalNode(data, reflect.ValueOf(o).Elem())
+}
+
+// jsonName returns the name of a type or field in JSON, like "lastCapture" for
+// LastCapture and "obis" for OBIS.
+func jsonName(name string) string {
+	if strings.ToUpper(name) == name {
+		return strings.ToLower(name)
+	}
+
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+
+	return string(r)
+}
+
+type jsonEncoder struct {
+	bytes.Buffer
+	positions bool
+}
+
+// node writes a pointer to a node as a JSON object, with the name of its type
+// if typed is set.
+func (e *jsonEncoder) node(v reflect.Value, typed bool) error {
+	if v.IsNil() {
+		e.WriteString("null")
+		return nil
+	}
+
+	v = v.Elem()
+	t := v.Type()
+
+	e.WriteByte('{')
+	sep := false
+	field := func(name string) {
+		if sep {
+			e.WriteByte(',')
+		}
+		sep = true
+		e.WriteString(`"` + name + `":`)
+	}
+
+	if typed {
+		field("type")
+		e.WriteString(`"` + jsonName(t.Name()) + `"`)
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := v.Field(i)
+
+		if f.Type() == positionType {
+			pos := f.Interface().(lexer.Position)
+			if !e.positions || pos == (lexer.Position{}) {
+				continue
+			}
+
+			field("pos")
+			data, _ := json.Marshal(jsonPosition(pos))
+			e.Write(data)
+			continue
+		}
+
+		if t.Field(i).Tag.Get("json") == "-" {
+			continue
+		}
+
+		field(jsonName(t.Field(i).Name))
+
+		// Numbers keep the digits of the meter.
+		if n, ok := v.Addr().Interface().(*Number); ok && f.Type() == bigFloatPointerType && n.Value != nil {
+			e.WriteString(`"` + encodeNumber(n) + `"`)
+			continue
+		}
+
+		if err := e.field(f); err != nil {
+			return err
+		}
+	}
+
+	e.WriteByte('}')
+
+	return nil
+}
+
+func (e *jsonEncoder) field(f reflect.Value) error {
+	switch {
+	case f.Type() == errorType:
+		if f.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		data, _ := json.Marshal(f.Interface().(error).Error())
+		e.Write(data)
+
+	case f.Type() == bigFloatPointerType:
+		if f.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		e.WriteString(`"` + f.Interface().(*big.Float).Text('f', -1) + `"`)
+
+	case f.Kind() == reflect.Interface:
+		if f.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		return e.node(f.Elem(), true)
+
+	case f.Kind() == reflect.Pointer:
+		return e.node(f, false)
+
+	case f.Kind() == reflect.Slice:
+		e.WriteByte('[')
+		for i := 0; i < f.Len(); i++ {
+			if i > 0 {
+				e.WriteByte(',')
+			}
+			if err := e.field(f.Index(i)); err != nil {
+				return err
+			}
+		}
+		e.WriteByte(']')
+
+	default:
+		data, err := json.Marshal(f.Interface())
+		if err != nil {
+			return err
+		}
+		e.Write(data)
+	}
+
+	return nil
+}
+
+// unmarshalNode decodes a JSON object into the fields of the node v.
+func unmarshalNode(data []byte, v reflect.Value) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := v.Field(i)
+
+		if f.Type() == positionType {
+			if data, ok := fields["pos"]; ok {
+				var pos jsonPosition
+				if err := json.Unmarshal(data, &pos); err != nil {
+					return fmt.Errorf("%s.pos: %w", jsonName(t.Name()), err)
+				}
+				f.Set(reflect.ValueOf(lexer.Position(pos)))
+			}
+			continue
+		}
+
+		if t.Field(i).Tag.Get("json") == "-" {
+			continue
+		}
+
+		name := jsonName(t.Field(i).Name)
+		data, ok := fields[name]
+		if !ok {
+			continue
+		}
+
+		if err := unmarshalField(data, f); err != nil {
+			return fmt.Errorf("%s.%s: %w", jsonName(t.Name()), name, err)
+		}
+	}
+
+	// Keep the digits of numbers in the format of the meter.
+	if n, ok := v.Addr().Interface().(*Number); ok {
+		var text string
+		if json.Unmarshal(fields["value"], &text) == nil && isDigits(text) {
+			n.Text = text
+		}
+	}
+
+	return nil
+}
+
+// isDigits reports whether s is a number in the format of the meter, like
+// "001581.123".
+func isDigits(s string) bool {
+	dot := false
+	for i, r := range s {
+		switch {
+		case r == '.' && !dot && i < len(s)-1:
+			dot = true
+		case r < '0' || r > '9':
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+func unmarshalField(data []byte, f reflect.Value) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		f.Set(reflect.Zero(f.Type()))
+		return nil
+	}
+
+	switch {
+	case f.Type() == errorType:
+		var msg string
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(errors.New(msg)))
+
+	case f.Type() == bigFloatPointerType:
+		// Digits with zero padding are no valid JSON numbers, so strings are
+		// taken as they are.
+		var n string
+		if err := json.Unmarshal(data, &n); err != nil {
+			var number json.Number
+			if err := json.Unmarshal(data, &number); err != nil {
+				return err
+			}
+			n = string(number)
+		}
+		// Meters only send finite decimal numbers.
+		v, _, err := new(big.Float).Parse(n, 10)
+		if err != nil || v.IsInf() {
+			return fmt.Errorf("invalid number %q", n)
+		}
+		f.Set(reflect.ValueOf(v))
+
+	case f.Kind() == reflect.Interface:
+		var typ struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &typ); err != nil {
+			return err
+		}
+
+		t, ok := valueTypes[typ.Type]
+		if !ok || !reflect.PointerTo(t).Implements(f.Type()) {
+			return fmt.Errorf("unknown value type %q", typ.Type)
+		}
+
+		v := reflect.New(t)
+		if err := unmarshalNode(data, v.Elem()); err != nil {
+			return err
+		}
+		f.Set(v)
+
+	case f.Kind() == reflect.Pointer:
+		v := reflect.New(f.Type().Elem())
+		if err := unmarshalNode(data, v.Elem()); err != nil {
+			return err
+		}
+		f.Set(v)
+
+	case f.Kind() == reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+
+		s := reflect.MakeSlice(f.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := unmarshalField(item, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		f.Set(s)
+
+	default:
+		return json.Unmarshal(data, f.Addr().Interface())
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package dsmr
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/alecthomas/repr"
+)
+
+func TestJSON(t *testing.T) {
+	for _, raw := range []string{telegramV22, telegramV30, telegramV42, telegramEMUCS, telegramSmarty} {
+		telegram, err := Parse(raw, VerifyChecksum(false))
+		assert.NoError(t, err)
+
+		data, err := json.Marshal(telegram)
+		assert.NoError(t, err)
+
+		actual := &Telegram{}
+		assert.NoError(t, json.Unmarshal(data, actual))
+		assert.Equal(t, repr.String(telegram), repr.String(actual))
+
+		// Without positions, only the positions get lost.
+		data, err = JSONOptions{}.Marshal(telegram)
+		assert.NoError(t, err)
+
+		actual = &Telegram{}
+		assert.NoError(t, json.Unmarshal(data, actual))
+		expected, err := telegram.MarshalText()
+		assert.NoError(t, err)
+		text, err := actual.MarshalText()
+		assert.NoError(t, err)
+		assert.Equal(t, string(expected), string(text))
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	telegram, err := Parse("/header\r\n1-0:1.8.1(001581.123*kWh)\r\n0-0:1.0.0(161113205757W)\r\n!\r\n")
+	assert.NoError(t, err)
+
+	data, err := JSONOptions{}.Marshal(telegram)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"header":{"value":"header"},"data":[`+
+		`{"obis":{"value":"1-0:1.8.1"},"value":{"type":"measurement","value":{"value":"001581.123"},"unit":{"value":"kWh"}}},`+
+		`{"obis":{"value":"0-0:1.0.0"},"value":{"type":"timestamp","value":"161113205757","dst":false}}],`+
+		`"footer":{"value":""}}`, string(data))
+
+	data, err = json.Marshal(telegram.Data[0])
+	assert.NoError(t, err)
+	assert.Equal(t, `{"pos":{"offset":9,"line":2,"column":1},"obis":{"pos":{"offset":9,"line":2,"column":1},"value":"1-0:1.8.1"},`+
+		`"value":{"type":"measurement","pos":{"offset":19,"line":2,"column":11},"value":{"pos":{"offset":19,"line":2,"column":11},"value":"001581.123"},`+
+		`"unit":{"pos":{"offset":30,"line":2,"column":22},"value":"kWh"}}}`, string(data))
+}
+
+func TestJSONMarshalValue(t *testing.T) {
+	telegram, err := Parse(telegramV42)
+	assert.NoError(t, err)
+
+	data, err := JSONOptions{}.MarshalValue(telegram.find("1-0:1.8.1").Value)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"measurement","value":{"value":"001581.123"},"unit":{"value":"kWh"}}`, string(data))
+
+	data, err = JSONOptions{}.MarshalValue(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "null", string(data))
+}
+
+func TestJSONUnmarshal(t *testing.T) {
+	obj := &Object{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"obis":{"value":"1-0:1.8.1"},"value":{"type":"tuple","value":[`+
+		`{"type":"measurement","value":{"value":1.5},"unit":{"value":"kW"}},{"type":"obis","value":"0-0:96.7.19"}]}}`), obj))
+
+	tuple := obj.Value.(*Tuple)
+	assert.Equal(t, 2, len(tuple.Value))
+	assert.Equal(t, "1.5", tuple.Value[0].(*Measurement).Value.Value.Text('f', -1))
+	assert.Equal(t, "0-0:96.7.19", tuple.Value[1].(*OBIS).Value)
+
+	err := json.Unmarshal([]byte(`{"value":{"type":"event"}}`), obj)
+	assert.EqualError(t, err, `object.value: unknown value type "event"`)
+
+	err = json.Unmarshal([]byte(`{"value":{"type":"tuple","value":[{"type":"eventLog"}]}}`), obj)
+	assert.EqualError(t, err, `object.value: tuple.value: unknown value type "eventLog"`)
+
+	for _, n := range []string{"1.2.3", "Inf", "-Inf", "0x10"} {
+		err = json.Unmarshal([]byte(`{"value":{"type":"number","value":"`+n+`"}}`), obj)
+		assert.EqualError(t, err, `object.value: number.value: invalid number "`+n+`"`)
+	}
+}
+
+func TestJSONRawObject(t *testing.T) {
+	telegram, perr := Parse("/header\r\n1-0:1.8.1(0001*kWh)()\r\n!\r\n", Lenient(true))
+	assert.Error(t, perr)
+
+	data, err := json.Marshal(telegram)
+	assert.NoError(t, err)
+
+	actual := &Telegram{}
+	assert.NoError(t, json.Unmarshal(data, actual))
+
+	raw := actual.Data[0].Value.(*RawObject)
+	assert.Equal(t, "(0001*kWh)()", raw.Value)
+	assert.Equal(t, perr.Error(), raw.Err.Error())
+}
@@ -0,0 +1,164 @@
+package dsmr
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// shift is the number of bytes removed from a telegram by blanking out lines,
+// for the content starting at offset.
+type shift struct {
+	offset int
+	n      int
+}
+
+// parseLenient parses a telegram by blanking out the lines of objects that
+// cannot be parsed and parsing it again. The blanked out lines are added to the
+// telegram as objects with a raw value.
+func parseLenient(str string, sum *uint16, opts *parseOptions) (*Telegram, error) {
+	var (
+		src    = str
+		shifts []shift
+		raws   []*Object
+	)
+
+	// original returns the offset in str of an offset in src.
+	original := func(offset int) int {
+		o := offset
+		for _, s := range shifts {
+			if offset >= s.offset {
+				o += s.n
+			}
+		}
+		return o
+	}
+
+	for {
+		t, err := parseGrammar(src)
+		if err == nil {
+			if len(shifts) > 0 {
+				Inspect(t, func(node Node) bool {
+					if !isNil(node) {
+						pos := reflect.Indirect(reflect.ValueOf(node)).FieldByName("Pos")
+						pos.FieldByName("Offset").SetInt(int64(original(node.Position().Offset)))
+					}
+					return true
+				})
+			}
+
+			t.Data = append(t.Data, raws...)
+			sort.SliceStable(t.Data, func(i, j int) bool { return t.Data[i].Pos.Offset < t.Data[j].Pos.Offset })
+
+			var errs ErrorList
+			for _, obj := range t.Data {
+				if raw, ok := obj.Value.(*RawObject); ok {
+					errs = append(errs, raw.Err)
+				}
+			}
+
+			if err := verifyChecksum(t, str, sum, opts); err != nil {
+				if len(errs) == 0 {
+					return t, err
+				}
+				errs = append(errs, err)
+			}
+			if len(errs) > 0 {
+				return t, errs
+			}
+
+			return t, nil
+		}
+
+		perr, ok := err.(participle.Error)
+		if !ok {
+			return nil, err
+		}
+
+		pos := perr.Position()
+		start, end, ok := objectLines(src, pos.Offset)
+		if !ok {
+			return nil, newParseError(src, err)
+		}
+
+		// Only errors in the value of an object can be recovered from.
+		err = newParseError(src, err)
+		switch err := err.(type) {
+		case *SyntaxError:
+			err.Pos.Offset = original(err.Pos.Offset)
+		case *UnknownValueError:
+			err.Pos.Offset = original(err.Pos.Offset)
+		default:
+			return nil, err
+		}
+
+		raw := src[start:end]
+		obj := &Object{
+			Pos: lexer.Position{
+				Offset: original(start),
+				Line:   pos.Line - strings.Count(src[start:pos.Offset], "\n"),
+				Column: 1,
+			},
+		}
+
+		value := &RawObject{Pos: obj.Pos, Value: raw, Err: err}
+		if code := obisPrefix.FindString(raw); code != "" {
+			obj.OBIS = &OBIS{Pos: obj.Pos, Value: code}
+			value.Value = raw[len(code):]
+			value.Pos.Offset += len(code)
+			value.Pos.Column += len(code)
+		}
+
+		obj.Value = value
+		raws = append(raws, obj)
+
+		// Keep the line breaks, so the lines of the other objects stay the same.
+		lines := strings.Count(raw, "\n")
+		n := len(raw) - 2*lines
+
+		for i := range shifts {
+			if shifts[i].offset > start {
+				shifts[i].offset -= n
+			}
+		}
+		shifts = append(shifts, shift{offset: start + 2*lines, n: n})
+
+		src = src[:start] + strings.Repeat("\r\n", lines) + src[end:]
+	}
+}
+
+// objectLines returns the start and end of the lines of the object an error at
+// offset occurred in, including the lines its value continues on. It reports
+// false if the error did not occur in an object, like in the header or footer.
+func objectLines(str string, offset int) (start, end int, ok bool) {
+	if offset >= len(str) {
+		return 0, 0, false
+	}
+
+	// Values of legacy captures continue on the next line.
+	start = strings.LastIndexByte(str[:offset], '\n') + 1
+	for start > 0 && str[start] == '(' {
+		start = strings.LastIndexByte(str[:start-1], '\n') + 1
+	}
+
+	if start == 0 || str[start] == '!' || str[start] == '\r' {
+		return 0, 0, false
+	}
+
+	end = offset
+	for {
+		i := strings.Index(str[end:], "\r\n")
+		if i < 0 {
+			return 0, 0, false
+		}
+
+		end += i
+		if !strings.HasPrefix(str[end+2:], "(") {
+			return start, end, true
+		}
+		end += 2
+	}
+}
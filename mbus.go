@@ -0,0 +1,139 @@
+package dsmr
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DeviceType is the type of an MBus device, as in 0-n:24.1.0.
+type DeviceType int
+
+const (
+	DeviceOther       DeviceType = 0
+	DeviceElectricity DeviceType = 2
+	DeviceGas         DeviceType = 3
+	DeviceHeat        DeviceType = 4
+	DeviceWarmWater   DeviceType = 6
+	DeviceWater       DeviceType = 7
+)
+
+func (d DeviceType) String() string {
+	switch d {
+	case DeviceOther:
+		return "other"
+	case DeviceElectricity:
+		return "electricity"
+	case DeviceGas:
+		return "gas"
+	case DeviceHeat:
+		return "heat"
+	case DeviceWarmWater:
+		return "warm water"
+	case DeviceWater:
+		return "water"
+	}
+
+	return fmt.Sprintf("DeviceType(%d)", int(d))
+}
+
+// MBusDevice is a device connected to the meter over MBus, like a gas, water
+// or heat meter, with the objects of its channel.
+type MBusDevice struct {
+	Channel     int
+	Type        DeviceType // 0-n:24.1.0
+	EquipmentID string     // 0-n:96.1.0, or 0-n:96.1.1 for e-MUCS
+
+	// Latest reading, from 0-n:24.2.1, 0-n:24.2.3 or 0-n:24.3.0.
+	Value     float64
+	Unit      string
+	Timestamp time.Time
+
+	Objects []*Object // All objects of the channel, in order of the telegram.
+}
+
+// MBusDevices groups the objects of MBus channels into devices, ordered by
+// their channel. Channels without objects are left out, and so are the fields
+// of empty objects. Objects that cannot be decoded do not fill the fields of
+// their device either. Their errors are returned in an [ErrorList], together
+// with all devices.
+func (t *Telegram) MBusDevices() ([]*MBusDevice, error) {
+	var (
+		devices []*MBusDevice
+		errs    ErrorList
+	)
+	channels := map[int]*MBusDevice{}
+
+	for _, obj := range t.Data {
+		if obj.OBIS == nil || isRaw(obj) {
+			continue
+		}
+
+		code, err := obj.OBIS.Code()
+		if err != nil || code.Medium != 0 || code.Channel == 0 {
+			continue
+		}
+
+		device, ok := channels[int(code.Channel)]
+		if !ok {
+			device = &MBusDevice{Channel: int(code.Channel)}
+			channels[device.Channel] = device
+			devices = append(devices, device)
+		}
+
+		device.Objects = append(device.Objects, obj)
+		if err := device.decode(obj, code); err != nil {
+			errs = append(errs, &DecodeError{OBIS: obj.Key(), Pos: obj.Pos, Err: err})
+		}
+	}
+
+	sort.SliceStable(devices, func(i, j int) bool { return devices[i].Channel < devices[j].Channel })
+
+	if len(errs) > 0 {
+		return devices, errs
+	}
+
+	return devices, nil
+}
+
+// decode decodes an object of the channel of the device into its fields.
+func (d *MBusDevice) decode(obj *Object, code OBISCode) error {
+	if obj.Value == nil {
+		return nil
+	}
+
+	switch [3]uint8{code.Indicator, code.Mode, code.Quantity} {
+	case [3]uint8{24, 1, 0}:
+		var typ int
+		if err := decodeInt(obj.Value, &typ); err != nil {
+			return err
+		}
+		d.Type = DeviceType(typ)
+	case [3]uint8{96, 1, 0}, [3]uint8{96, 1, 1}:
+		return decodeString(obj.Value, &d.EquipmentID)
+	case [3]uint8{24, 2, 1}, [3]uint8{24, 2, 3}, [3]uint8{24, 3, 0}:
+		var (
+			value     float64
+			timestamp time.Time
+		)
+
+		if err := decodeCapture(obj.Value, &value, &timestamp); err != nil {
+			return err
+		}
+
+		// Devices may report several captures, like hourly and 5-minute values.
+		if !d.Timestamp.IsZero() && !timestamp.After(d.Timestamp) {
+			return nil
+		}
+
+		d.Value, d.Timestamp = value, timestamp
+		switch v := obj.Value.(type) {
+		case *LastCapture:
+			d.Unit = v.Value.Unit.Value
+		case *LegacyLastCapture:
+			d.Unit = v.Value.Unit.Value
+		}
+	}
+
+	return nil
+}
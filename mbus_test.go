@@ -0,0 +1,89 @@
+package dsmr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestMBusDevices(t *testing.T) {
+	raw := strings.Replace(telegramV42, "!6796\r\n", ""+
+		"0-2:24.1.0(007)\r\n"+
+		"0-2:96.1.0(3232323241424344313233343536373839)\r\n"+
+		"0-2:24.2.1(161129200500W)(00012.345*m3)\r\n"+
+		"0-3:24.1.0(004)\r\n"+
+		"0-3:24.2.1(161129195500W)(00001.250*GJ)\r\n"+
+		"0-3:24.2.1(161129200000W)(00001.500*GJ)\r\n"+
+		"!\r\n", 1)
+
+	telegram, err := Parse(raw)
+	assert.NoError(t, err)
+
+	devices, err := telegram.MBusDevices()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(devices))
+
+	cet := time.FixedZone("CET", 60*60)
+
+	gas := devices[0]
+	assert.Equal(t, 1, gas.Channel)
+	assert.Equal(t, DeviceGas, gas.Type)
+	assert.Equal(t, "4819243993373755377509728609491464", gas.EquipmentID)
+	assert.Equal(t, 981.443, gas.Value)
+	assert.Equal(t, "m3", gas.Unit)
+	assert.True(t, time.Date(2016, 11, 29, 20, 0, 0, 0, cet).Equal(gas.Timestamp))
+	assert.Equal(t, 3, len(gas.Objects))
+
+	water := devices[1]
+	assert.Equal(t, 2, water.Channel)
+	assert.Equal(t, DeviceWater, water.Type)
+	assert.Equal(t, 12.345, water.Value)
+
+	heat := devices[2]
+	assert.Equal(t, DeviceHeat, heat.Type)
+	assert.Equal(t, "heat", heat.Type.String())
+	assert.Equal(t, 1.5, heat.Value)
+	assert.Equal(t, "GJ", heat.Unit)
+}
+
+func TestMBusDevicesLegacy(t *testing.T) {
+	telegram, err := Parse(telegramV22)
+	assert.NoError(t, err)
+
+	devices, err := telegram.MBusDevices()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(devices))
+	assert.Equal(t, DeviceGas, devices[0].Type)
+	assert.Equal(t, 1.001, devices[0].Value)
+	assert.Equal(t, "m3", devices[0].Unit)
+	assert.Equal(t, 2016, devices[0].Timestamp.Year())
+}
+
+func TestMBusDevicesError(t *testing.T) {
+	telegram, err := Parse("" +
+		"/header\r\n" +
+		"0-1:24.1.0(gas)\r\n" +
+		"0-2:24.1.0(003)\r\n" +
+		"0-2:24.2.1()\r\n" +
+		"0-2:24.2.1(161129200000W)(00981.443*m3)\r\n" +
+		"0-2:24.2.1(1.5)\r\n" +
+		"!\r\n")
+	assert.NoError(t, err)
+
+	devices, err := telegram.MBusDevices()
+
+	var errs ErrorList
+	assert.True(t, errors.As(err, &errs))
+	assert.Equal(t, 2, len(errs))
+	assert.EqualError(t, errs[0], `2:1: cannot decode 0-1:24.1.0: strconv.Atoi: parsing "gas": invalid syntax`)
+	assert.EqualError(t, errs[1], `6:1: cannot decode 0-2:24.2.1: unexpected value *dsmr.String`)
+
+	assert.Equal(t, 2, len(devices))
+	assert.Equal(t, DeviceOther, devices[0].Type)
+	assert.Equal(t, DeviceGas, devices[1].Type)
+	assert.Equal(t, 981.443, devices[1].Value)
+	assert.Equal(t, 4, len(devices[1].Objects))
+}
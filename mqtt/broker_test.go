@@ -0,0 +1,125 @@
+package mqtt
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"testing"
+)
+
+// message is a message published to the test broker.
+type message struct {
+	Topic   string
+	Payload string
+	Retain  bool
+}
+
+// broker is a minimal in-process MQTT broker, which accepts all connections
+// and records the messages published to it.
+type broker struct {
+	ln net.Listener
+
+	// Code is the return code of CONNACK packets, 0 to accept connections.
+	Code byte
+
+	// IgnorePings leaves pings unanswered.
+	IgnorePings bool
+
+	mu       sync.Mutex
+	messages []message
+	packets  []byte   // Types of all packets received.
+	connects [][]byte // Bodies of CONNECT packets received.
+	done     chan struct{}
+}
+
+func newBroker(t *testing.T) *broker {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &broker{ln: ln, done: make(chan struct{})}
+	t.Cleanup(func() { ln.Close() })
+
+	go b.serve()
+
+	return b
+}
+
+func (b *broker) Addr() string { return b.ln.Addr().String() }
+
+func (b *broker) serve() {
+	for {
+		conn, err := b.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go b.handle(conn)
+	}
+}
+
+func (b *broker) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for {
+		typ, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+
+		b.mu.Lock()
+		b.packets = append(b.packets, typ&0xF0)
+		b.mu.Unlock()
+
+		switch typ & 0xF0 {
+		case packetConnect:
+			b.mu.Lock()
+			b.connects = append(b.connects, body)
+			code := b.Code
+			b.mu.Unlock()
+
+			_, _ = conn.Write([]byte{packetConnAck, 2, 0, code})
+		case packetPublish:
+			n := int(body[0])<<8 | int(body[1])
+
+			b.mu.Lock()
+			b.messages = append(b.messages, message{
+				Topic:   string(body[2 : 2+n]),
+				Payload: string(body[2+n:]),
+				Retain:  typ&0x01 != 0,
+			})
+			b.mu.Unlock()
+		case packetPingReq:
+			b.mu.Lock()
+			ignore := b.IgnorePings
+			b.mu.Unlock()
+
+			if !ignore {
+				_, _ = conn.Write([]byte{packetPingResp, 0})
+			}
+		case packetDisconnect:
+			close(b.done)
+			return
+		}
+	}
+}
+
+// Messages returns the messages published so far.
+func (b *broker) Messages() []message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]message(nil), b.messages...)
+}
+
+// Packets returns the types of the packets received so far.
+func (b *broker) Packets() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]byte(nil), b.packets...)
+}
@@ -0,0 +1,347 @@
+// Package mqtt publishes telegrams to an MQTT broker, as a state topic per
+// object and a combined JSON payload, and announces them to Home Assistant
+// with MQTT discovery:
+//
+//	c, err := mqtt.Dial("localhost:1883", mqtt.Config{ClientID: "dsmr"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer c.Close()
+//
+//	p := mqtt.NewPublisher(c, "dsmr")
+//	err = p.Publish(telegram)
+package mqtt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// Packet types of MQTT 3.1.1, in the upper bits of the first byte of a packet.
+const (
+	packetConnect    = 0x10
+	packetConnAck    = 0x20
+	packetPublish    = 0x30
+	packetPingReq    = 0xC0
+	packetPingResp   = 0xD0
+	packetDisconnect = 0xE0
+)
+
+// errNoPingResp is the error of a connection that was lost because the broker
+// did not answer a ping in time.
+var errNoPingResp = errors.New("no answer to ping")
+
+// connectTimeout limits connecting to a broker and waiting for its answer.
+const connectTimeout = 10 * time.Second
+
+// Config holds the settings of the connection to a broker.
+type Config struct {
+	ClientID string
+	Username string
+	Password string
+
+	// KeepAlive is the maximum time between packets sent to the broker, which
+	// pings it when nothing is published in time. The connection is lost when
+	// the broker does not answer a ping within this time. Zero disables keep
+	// alive.
+	KeepAlive time.Duration
+}
+
+// ConnectError is returned when the broker refuses a connection.
+type ConnectError struct {
+	Code byte
+}
+
+func (e *ConnectError) Error() string {
+	switch e.Code {
+	case 1:
+		return "mqtt: connection refused: unacceptable protocol version"
+	case 2:
+		return "mqtt: connection refused: identifier rejected"
+	case 3:
+		return "mqtt: connection refused: server unavailable"
+	case 4:
+		return "mqtt: connection refused: bad user name or password"
+	case 5:
+		return "mqtt: connection refused: not authorized"
+	}
+
+	return fmt.Sprintf("mqtt: connection refused: code %d", e.Code)
+}
+
+// Client is a minimal MQTT 3.1.1 client, which publishes messages with QoS 0.
+// It is safe for concurrent use.
+type Client struct {
+	conn net.Conn
+	cfg  Config
+
+	mu      sync.Mutex
+	last    time.Time // Time the last packet was sent.
+	pinging bool      // Whether a ping awaits its answer.
+	err     error     // Error that ended reading from the broker, if any.
+	done    chan struct{}
+}
+
+// Dial connects to the broker at addr.
+func Dial(addr string, cfg Config) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, connectTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := Connect(conn, cfg)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Connect connects to a broker over an established connection, like a TLS
+// connection. A password requires a user name.
+func Connect(conn net.Conn, cfg Config) (*Client, error) {
+	if cfg.Password != "" && cfg.Username == "" {
+		return nil, errors.New("mqtt: password without user name")
+	}
+
+	c := &Client{conn: conn, cfg: cfg, done: make(chan struct{})}
+
+	if err := conn.SetDeadline(time.Now().Add(connectTimeout)); err != nil {
+		return nil, err
+	}
+
+	if err := c.write(packetConnect, c.connect()); err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(conn)
+	typ, body, err := readPacket(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ&0xF0 != packetConnAck || len(body) != 2 {
+		return nil, fmt.Errorf("mqtt: unexpected packet type 0x%02X", typ)
+	}
+
+	if body[1] != 0 {
+		return nil, &ConnectError{Code: body[1]}
+	}
+
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	go c.read(r)
+
+	if cfg.KeepAlive > 0 {
+		go c.keepAlive()
+	}
+
+	return c, nil
+}
+
+// connect returns the body of the CONNECT packet.
+func (c *Client) connect() []byte {
+	flags := byte(0x02) // Clean session.
+	if c.cfg.Username != "" {
+		flags |= 0x80
+	}
+	if c.cfg.Password != "" {
+		flags |= 0x40
+	}
+
+	keepAlive := uint16(c.cfg.KeepAlive / time.Second)
+
+	b := appendString(nil, "MQTT")
+	b = append(b, 4, flags, byte(keepAlive>>8), byte(keepAlive))
+	b = appendString(b, c.cfg.ClientID)
+	if c.cfg.Username != "" {
+		b = appendString(b, c.cfg.Username)
+	}
+	if c.cfg.Password != "" {
+		b = appendString(b, c.cfg.Password)
+	}
+
+	return b
+}
+
+// read reads the packets of the broker, as answers to pings are the only
+// packets it sends, and records the error once the connection is lost.
+func (c *Client) read(r *bufio.Reader) {
+	var err error
+	for {
+		var typ byte
+		if typ, _, err = readPacket(r); err != nil {
+			break
+		}
+
+		if typ&0xF0 == packetPingResp {
+			c.mu.Lock()
+			c.pinging = false
+			err = c.conn.SetReadDeadline(time.Time{})
+			c.mu.Unlock()
+
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		err = errNoPingResp
+	}
+
+	select {
+	case <-c.done:
+		// Closing the connection ends reading.
+	default:
+		c.mu.Lock()
+		c.err = fmt.Errorf("mqtt: connection lost: %w", err)
+		c.mu.Unlock()
+
+		c.conn.Close()
+	}
+}
+
+// Publish publishes a message with QoS 0. Retained messages are kept by the
+// broker and sent to clients that subscribe later. Once the connection to the
+// broker is lost, its error is returned.
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	typ := byte(packetPublish)
+	if retain {
+		typ |= 0x01
+	}
+
+	return c.write(typ, append(appendString(nil, topic), payload...))
+}
+
+// Close disconnects from the broker and closes the connection. It returns the
+// error of the connection if it was lost before.
+func (c *Client) Close() error {
+	select {
+	case <-c.done:
+		return nil
+	default:
+		close(c.done)
+	}
+
+	err := c.write(packetDisconnect, nil)
+	if cerr := c.conn.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// keepAlive pings the broker whenever nothing was sent for half the keep alive
+// time, until the client is closed. The broker has to answer a ping within the
+// keep alive time, and no other ping is sent until it does.
+func (c *Client) keepAlive() {
+	ticker := time.NewTicker(c.cfg.KeepAlive / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case now := <-ticker.C:
+			c.mu.Lock()
+			ping := !c.pinging && now.Sub(c.last) >= c.cfg.KeepAlive/2
+			if ping {
+				c.pinging = true
+				_ = c.conn.SetReadDeadline(now.Add(c.cfg.KeepAlive))
+			}
+			c.mu.Unlock()
+
+			if ping {
+				if err := c.write(packetPingReq, nil); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// write writes a packet with the given type and flags in its first byte.
+func (c *Client) write(typ byte, body []byte) error {
+	b := append([]byte{typ}, appendLength(nil, len(body))...)
+	b = append(b, body...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	if _, err := c.conn.Write(b); err != nil {
+		return err
+	}
+	c.last = time.Now()
+
+	return nil
+}
+
+// appendString appends a string prefixed with its length.
+func appendString(b []byte, s string) []byte {
+	return append(append(b, byte(len(s)>>8), byte(len(s))), s...)
+}
+
+// appendLength appends the remaining length of a packet, encoded in groups of
+// 7 bits with the highest bit set on all but the last.
+func appendLength(b []byte, n int) []byte {
+	for {
+		digit := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			digit |= 0x80
+		}
+
+		b = append(b, digit)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+// readPacket reads a packet and returns the first byte and body of it.
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	typ, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	n, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("mqtt: malformed remaining length")
+		}
+
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		n += int(digit&0x7F) * multiplier
+		multiplier *= 128
+
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+
+	return typ, body, nil
+}
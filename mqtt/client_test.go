@@ -0,0 +1,121 @@
+package mqtt
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestClient(t *testing.T) {
+	b := newBroker(t)
+
+	c, err := Dial(b.Addr(), Config{ClientID: "dsmr", Username: "user", Password: "secret", KeepAlive: time.Minute})
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Publish("dsmr/test", []byte("hello"), true))
+	assert.NoError(t, c.Close())
+	assert.NoError(t, c.Close())
+	<-b.done
+
+	assert.Equal(t, []message{{Topic: "dsmr/test", Payload: "hello", Retain: true}}, b.Messages())
+	assert.Equal(t, "\x00\x04MQTT\x04\xC2\x00\x3C\x00\x04dsmr\x00\x04user\x00\x06secret", string(b.connects[0]))
+}
+
+func TestClientKeepAlive(t *testing.T) {
+	b := newBroker(t)
+
+	c, err := Dial(b.Addr(), Config{ClientID: "dsmr", KeepAlive: 20 * time.Millisecond})
+	assert.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, c.Close())
+	<-b.done
+
+	assert.Contains(t, string(b.Packets()), string([]byte{packetPingReq}))
+}
+
+func TestClientPingUnanswered(t *testing.T) {
+	b := newBroker(t)
+	b.IgnorePings = true
+
+	c, err := Dial(b.Addr(), Config{ClientID: "dsmr", KeepAlive: 20 * time.Millisecond})
+	assert.NoError(t, err)
+
+	for lost := false; !lost; {
+		time.Sleep(time.Millisecond)
+
+		c.mu.Lock()
+		lost = c.err != nil
+		c.mu.Unlock()
+	}
+
+	err = c.Publish("dsmr/test", []byte("hello"), false)
+	assert.EqualError(t, err, "mqtt: connection lost: no answer to ping")
+
+	// No other ping is sent while waiting for the answer.
+	pings := 0
+	for _, typ := range b.Packets() {
+		if typ == packetPingReq {
+			pings++
+		}
+	}
+	assert.Equal(t, 1, pings)
+}
+
+func TestClientRefused(t *testing.T) {
+	b := newBroker(t)
+	b.Code = 5
+
+	_, err := Dial(b.Addr(), Config{ClientID: "dsmr"})
+	assert.EqualError(t, err, "mqtt: connection refused: not authorized")
+}
+
+func TestClientPasswordWithoutUsername(t *testing.T) {
+	_, err := Dial(newBroker(t).Addr(), Config{ClientID: "dsmr", Password: "secret"})
+	assert.EqualError(t, err, "mqtt: password without user name")
+}
+
+func TestClientConnectionLost(t *testing.T) {
+	conn, broker := net.Pipe()
+
+	go func() {
+		r := bufio.NewReader(broker)
+		if _, _, err := readPacket(r); err == nil {
+			_, _ = broker.Write([]byte{packetConnAck, 2, 0, 0})
+		}
+		broker.Close()
+	}()
+
+	c, err := Connect(conn, Config{ClientID: "dsmr"})
+	assert.NoError(t, err)
+
+	for lost := false; !lost; {
+		time.Sleep(time.Millisecond)
+
+		c.mu.Lock()
+		lost = c.err != nil
+		c.mu.Unlock()
+	}
+
+	err = c.Publish("dsmr/test", []byte("hello"), false)
+	assert.EqualError(t, err, "mqtt: connection lost: EOF")
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.EqualError(t, c.Close(), "mqtt: connection lost: EOF")
+}
+
+func TestAppendLength(t *testing.T) {
+	for n, expected := range map[int][]byte{
+		0:         {0x00},
+		127:       {0x7F},
+		128:       {0x80, 0x01},
+		16383:     {0xFF, 0x7F},
+		268435455: {0xFF, 0xFF, 0xFF, 0x7F},
+	} {
+		assert.Equal(t, expected, appendLength(nil, n))
+	}
+}
@@ -0,0 +1,277 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/robinvdvleuten/dsmr"
+)
+
+// Publisher publishes telegrams to topics below a prefix: the state of each
+// object to "<topic>/<OBIS code>" and all states combined as a JSON object to
+// "<topic>/state". Numbers keep the digits sent by the meter, and are strings
+// in the combined payload like all other states. Objects are announced to Home Assistant the first time
+// they are published.
+type Publisher struct {
+	Topic string
+
+	// DiscoveryPrefix is the prefix of the Home Assistant discovery topics.
+	// Discovery is disabled when it is empty.
+	DiscoveryPrefix string
+
+	// NodeID identifies the meter in discovery topics and unique IDs. It
+	// defaults to the equipment identifier of the meter.
+	NodeID string
+
+	client    *Client
+	announced map[string]bool
+}
+
+// NewPublisher returns a publisher publishing below topic, which announces
+// objects to Home Assistant under the default "homeassistant" prefix.
+func NewPublisher(c *Client, topic string) *Publisher {
+	return &Publisher{
+		Topic:           topic,
+		DiscoveryPrefix: "homeassistant",
+		client:          c,
+		announced:       map[string]bool{},
+	}
+}
+
+// state is the state of an object as published.
+type state struct {
+	value     string
+	unit      string // Unit of the state, if any.
+	timestamp bool
+}
+
+// Publish publishes the states of the objects of a telegram. Objects without
+// a single state, like event logs, are only part of the combined payload, in
+// which they are encoded like by [dsmr.JSONOptions.MarshalValue].
+func (p *Publisher) Publish(t *dsmr.Telegram) error {
+	version := t.Version()
+	combined := map[string]any{}
+
+	// Devices are returned even when some of their objects cannot be decoded.
+	devices := map[string]dsmr.DeviceType{}
+	mbus, _ := t.MBusDevices()
+	for _, device := range mbus {
+		for _, obj := range device.Objects {
+			devices[obj.Key()] = device.Type
+		}
+	}
+
+	for _, obj := range t.Data {
+		key := obj.Key()
+		if key == "" {
+			continue
+		}
+
+		s, ok := stateOf(obj.Value)
+		if !ok {
+			if obj.Value == nil {
+				continue
+			}
+
+			data, err := dsmr.JSONOptions{}.MarshalValue(obj.Value)
+			if err != nil {
+				return err
+			}
+			combined[key] = json.RawMessage(data)
+			continue
+		}
+
+		if v, ok := obj.Value.(*dsmr.String); ok && kind(obj) == dsmr.KindText {
+			s.value = dsmr.DecodeText(v.Value, version).Value
+		}
+
+		if p.DiscoveryPrefix != "" && !p.announced[key] {
+			if err := p.announce(t, obj, s, devices[key]); err != nil {
+				return err
+			}
+			p.announced[key] = true
+		}
+
+		if err := p.client.Publish(p.Topic+"/"+key, []byte(s.value), false); err != nil {
+			return err
+		}
+
+		combined[key] = s.value
+	}
+
+	payload, err := json.Marshal(combined)
+	if err != nil {
+		return err
+	}
+
+	return p.client.Publish(p.Topic+"/state", payload, false)
+}
+
+// stateOf returns the state of a value, and reports false if it has no single
+// state.
+func stateOf(v dsmr.Value) (state, bool) {
+	switch v := v.(type) {
+	case *dsmr.Measurement:
+		return state{value: digits(v.Value), unit: v.Unit.Value}, true
+	case *dsmr.Number:
+		return state{value: digits(v)}, true
+	case *dsmr.String:
+		return state{value: v.Value}, true
+	case *dsmr.Timestamp:
+		t, err := v.Time()
+		if err != nil {
+			return state{}, false
+		}
+		return state{value: t.Format(time.RFC3339), timestamp: true}, true
+	case *dsmr.LastCapture:
+		return stateOf(v.Value)
+	case *dsmr.LegacyLastCapture:
+		return state{value: digits(v.Value.Value), unit: v.Value.Unit.Value}, true
+	}
+
+	return state{}, false
+}
+
+// digits returns the digits of a number as sent by the meter, unless its value
+// changed since, like [dsmr.JSONOptions.MarshalValue] encodes them.
+func digits(n *dsmr.Number) string {
+	if n.Text != "" {
+		if f, ok := new(big.Float).SetString(n.Text); ok && f.Cmp(n.Value) == 0 {
+			return n.Text
+		}
+	}
+
+	return n.Value.Text('f', -1)
+}
+
+// kind returns the kind of value of an object in the OBIS registry.
+func kind(obj *dsmr.Object) dsmr.ValueKind {
+	code, err := obj.OBIS.Code()
+	if err != nil {
+		return dsmr.KindString
+	}
+
+	info, _ := dsmr.LookupOBIS(code)
+	return info.Kind
+}
+
+// discovery is the Home Assistant discovery config of a sensor.
+type discovery struct {
+	Name              string          `json:"name"`
+	UniqueID          string          `json:"unique_id"`
+	StateTopic        string          `json:"state_topic"`
+	UnitOfMeasurement string          `json:"unit_of_measurement,omitempty"`
+	DeviceClass       string          `json:"device_class,omitempty"`
+	StateClass        string          `json:"state_class,omitempty"`
+	Device            discoveryDevice `json:"device"`
+}
+
+type discoveryDevice struct {
+	Identifiers []string `json:"identifiers"`
+	Name        string   `json:"name"`
+	Model       string   `json:"model,omitempty"`
+	SWVersion   string   `json:"sw_version,omitempty"`
+}
+
+// announce publishes the retained discovery config of an object.
+func (p *Publisher) announce(t *dsmr.Telegram, obj *dsmr.Object, s state, device dsmr.DeviceType) error {
+	node := p.nodeID(t)
+	id := topicID(obj.Key())
+
+	config := discovery{
+		Name:       obj.Key(),
+		UniqueID:   node + "_" + id,
+		StateTopic: p.Topic + "/" + obj.Key(),
+		Device: discoveryDevice{
+			Identifiers: []string{node},
+			Name:        "Smart meter",
+		},
+	}
+
+	if t.Header != nil {
+		config.Device.Model = t.Header.Value
+	}
+	if version := t.Version(); version != dsmr.VersionUnknown {
+		config.Device.SWVersion = "DSMR " + version.String()
+	}
+
+	if code, err := obj.OBIS.Code(); err == nil {
+		if info, ok := dsmr.LookupOBIS(code); ok {
+			config.Name = info.Description
+		}
+	}
+
+	switch {
+	case s.timestamp:
+		config.DeviceClass = "timestamp"
+	case s.unit != "":
+		config.UnitOfMeasurement, config.DeviceClass, config.StateClass = classify(s.unit, device)
+	}
+
+	payload, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	return p.client.Publish(p.DiscoveryPrefix+"/sensor/"+node+"/"+id+"/config", payload, true)
+}
+
+// classify returns the unit, device class and state class of Home Assistant
+// for a unit of a telegram. Volumes are gas or water, going by the type of the
+// MBus device they were measured by.
+func classify(unit string, device dsmr.DeviceType) (string, string, string) {
+	u, err := dsmr.ParseUnit(unit)
+	if err != nil {
+		return unit, "", "measurement"
+	}
+
+	switch u {
+	case dsmr.UnitWh, dsmr.UnitKWh, dsmr.UnitGJ:
+		return u.String(), "energy", "total_increasing"
+	case dsmr.UnitW, dsmr.UnitKW:
+		return u.String(), "power", "measurement"
+	case dsmr.UnitV:
+		return "V", "voltage", "measurement"
+	case dsmr.UnitA:
+		return "A", "current", "measurement"
+	case dsmr.UnitM3:
+		if device == dsmr.DeviceWater {
+			return "m³", "water", "total_increasing"
+		}
+		return "m³", "gas", "total_increasing"
+	case dsmr.UnitS:
+		return "s", "duration", "measurement"
+	case dsmr.UnitKvarh:
+		return "kvarh", "reactive_energy", "total_increasing"
+	case dsmr.UnitKvar:
+		return "kvar", "reactive_power", "measurement"
+	}
+
+	return unit, "", "measurement"
+}
+
+// nodeID returns the ID of the meter in discovery topics.
+func (p *Publisher) nodeID(t *dsmr.Telegram) string {
+	if p.NodeID != "" {
+		return p.NodeID
+	}
+
+	if id, ok := t.EquipmentID(); ok && topicID(id.Value) != "" {
+		return topicID(id.Value)
+	}
+
+	return "dsmr"
+}
+
+// topicID returns s with all characters that are not allowed in the IDs of
+// discovery topics replaced by underscores.
+func topicID(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(s))
+}
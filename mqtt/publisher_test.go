@@ -0,0 +1,119 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/robinvdvleuten/dsmr"
+)
+
+const telegram = "" +
+	"/KFM5KAIFA-METER\r\n" +
+	"\r\n" +
+	"1-3:0.2.8(42)\r\n" +
+	"0-0:1.0.0(161113205757W)\r\n" +
+	"0-0:96.1.1(4B384547303034303436333935353037)\r\n" +
+	"1-0:1.8.1(001581.123*kWh)\r\n" +
+	"1-0:1.7.0(02.027*kW)\r\n" +
+	"1-0:99.97.0(1)(0-0:96.7.19)(000104180320W)(0000237126*s)\r\n" +
+	"0-1:24.1.0(003)\r\n" +
+	"0-1:24.2.1(161129200000W)(00981.443*m3)\r\n" +
+	"0-2:24.1.0(007)\r\n" +
+	"0-2:24.2.1(161129200000W)(00012.5*m3)\r\n" +
+	"!\r\n"
+
+func publish(t *testing.T, p func(c *Client) *Publisher, telegrams int) []message {
+	t.Helper()
+
+	b := newBroker(t)
+
+	c, err := Dial(b.Addr(), Config{ClientID: "dsmr"})
+	assert.NoError(t, err)
+
+	tg, err := dsmr.Parse(telegram)
+	assert.NoError(t, err)
+
+	publisher := p(c)
+	for i := 0; i < telegrams; i++ {
+		assert.NoError(t, publisher.Publish(tg))
+	}
+
+	assert.NoError(t, c.Close())
+	<-b.done
+
+	return b.Messages()
+}
+
+func TestPublisher(t *testing.T) {
+	messages := publish(t, func(c *Client) *Publisher {
+		p := NewPublisher(c, "dsmr")
+		p.DiscoveryPrefix = ""
+		return p
+	}, 1)
+
+	assert.Equal(t, []message{
+		{Topic: "dsmr/1-3:0.2.8", Payload: "42"},
+		{Topic: "dsmr/0-0:1.0.0", Payload: "2016-11-13T20:57:57+01:00"},
+		{Topic: "dsmr/0-0:96.1.1", Payload: "K8EG004046395507"},
+		{Topic: "dsmr/1-0:1.8.1", Payload: "001581.123"},
+		{Topic: "dsmr/1-0:1.7.0", Payload: "02.027"},
+		{Topic: "dsmr/0-1:24.1.0", Payload: "003"},
+		{Topic: "dsmr/0-1:24.2.1", Payload: "00981.443"},
+		{Topic: "dsmr/0-2:24.1.0", Payload: "007"},
+		{Topic: "dsmr/0-2:24.2.1", Payload: "00012.5"},
+		{Topic: "dsmr/state", Payload: `{"0-0:1.0.0":"2016-11-13T20:57:57+01:00","0-0:96.1.1":"K8EG004046395507",` +
+			`"0-1:24.1.0":"003","0-1:24.2.1":"00981.443","0-2:24.1.0":"007","0-2:24.2.1":"00012.5","1-0:1.7.0":"02.027",` +
+			`"1-0:1.8.1":"001581.123","1-0:99.97.0":{"type":"eventLog","count":{"value":"1"},"obis":{"value":"0-0:96.7.19"},` +
+			`"value":[{"timestamp":{"value":"000104180320","dst":false},"value":{"value":{"value":"0000237126"},"unit":{"value":"s"}}}]},` +
+			`"1-3:0.2.8":"42"}`},
+	}, messages)
+}
+
+func TestPublisherDiscovery(t *testing.T) {
+	messages := publish(t, func(c *Client) *Publisher { return NewPublisher(c, "dsmr") }, 2)
+
+	configs := map[string]discovery{}
+	for _, m := range messages {
+		if m.Retain {
+			var config discovery
+			assert.NoError(t, json.Unmarshal([]byte(m.Payload), &config))
+			configs[m.Topic] = config
+		}
+	}
+
+	// Objects are only announced once.
+	assert.Equal(t, 9, len(configs))
+	assert.Equal(t, 2*10+9, len(messages))
+
+	assert.Equal(t, discovery{
+		Name:              "Electricity delivered (tariff 1)",
+		UniqueID:          "K8EG004046395507_1-0_1_8_1",
+		StateTopic:        "dsmr/1-0:1.8.1",
+		UnitOfMeasurement: "kWh",
+		DeviceClass:       "energy",
+		StateClass:        "total_increasing",
+		Device: discoveryDevice{
+			Identifiers: []string{"K8EG004046395507"},
+			Name:        "Smart meter",
+			Model:       "KFM5KAIFA-METER",
+			SWVersion:   "DSMR 4.2",
+		},
+	}, configs["homeassistant/sensor/K8EG004046395507/1-0_1_8_1/config"])
+
+	power := configs["homeassistant/sensor/K8EG004046395507/1-0_1_7_0/config"]
+	assert.Equal(t, "power", power.DeviceClass)
+	assert.Equal(t, "measurement", power.StateClass)
+
+	gas := configs["homeassistant/sensor/K8EG004046395507/0-1_24_2_1/config"]
+	assert.Equal(t, "m³", gas.UnitOfMeasurement)
+	assert.Equal(t, "gas", gas.DeviceClass)
+
+	water := configs["homeassistant/sensor/K8EG004046395507/0-2_24_2_1/config"]
+	assert.Equal(t, "water", water.DeviceClass)
+	assert.Equal(t, "total_increasing", water.StateClass)
+
+	timestamp := configs["homeassistant/sensor/K8EG004046395507/0-0_1_0_0/config"]
+	assert.Equal(t, "timestamp", timestamp.DeviceClass)
+	assert.Equal(t, "", timestamp.StateClass)
+}
@@ -0,0 +1,184 @@
+package dsmr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OBISCode is a parsed OBIS (Object Identification System) code. Codes are
+// written as A-B:C.D.E, optionally followed by *F.
+type OBISCode struct {
+	Medium    uint8 // A: 0 for abstract objects, 1 for electricity.
+	Channel   uint8 // B: 0 for the meter itself, 1-4 for MBus devices.
+	Indicator uint8 // C: the physical quantity, like active power or voltage.
+	Mode      uint8 // D: the type of measurement, like actual or cumulative.
+	Quantity  uint8 // E: further classification, like the tariff.
+	Storage   uint8 // F: historical values, 255 when absent.
+}
+
+// ParseOBISCode parses an OBIS code like "1-0:1.8.1".
+func ParseOBISCode(s string) (OBISCode, error) {
+	var groups [6]uint8
+	groups[5] = 255
+
+	rest := s
+	for i, sep := range []string{"-", ":", ".", ".", "*"} {
+		group, next, found := strings.Cut(rest, sep)
+		// Only the storage group (F) is optional.
+		if !found && i < 4 {
+			return OBISCode{}, fmt.Errorf("invalid OBIS code %q", s)
+		}
+
+		v, err := strconv.ParseUint(group, 10, 8)
+		if err != nil {
+			return OBISCode{}, fmt.Errorf("invalid OBIS code %q", s)
+		}
+
+		groups[i] = uint8(v)
+		rest = next
+
+		if !found {
+			return OBISCode{groups[0], groups[1], groups[2], groups[3], groups[4], groups[5]}, nil
+		}
+	}
+
+	v, err := strconv.ParseUint(rest, 10, 8)
+	if err != nil {
+		return OBISCode{}, fmt.Errorf("invalid OBIS code %q", s)
+	}
+	groups[5] = uint8(v)
+
+	return OBISCode{groups[0], groups[1], groups[2], groups[3], groups[4], groups[5]}, nil
+}
+
+func (c OBISCode) String() string {
+	s := fmt.Sprintf("%d-%d:%d.%d.%d", c.Medium, c.Channel, c.Indicator, c.Mode, c.Quantity)
+	if c.Storage != 255 {
+		s += fmt.Sprintf("*%d", c.Storage)
+	}
+
+	return s
+}
+
+// Code parses the OBIS code of the node.
+func (o *OBIS) Code() (OBISCode, error) {
+	return ParseOBISCode(o.Value)
+}
+
+// ValueKind is the kind of value an object is expected to have.
+type ValueKind int
+
+const (
+	KindString ValueKind = iota
+	KindMeasurement
+	KindTimestamp
+	KindEventLog
+	KindLastCapture
+	KindLegacyLastCapture
+	KindPeakLog
+	KindText // String encoded as hex in DSMR 3.0 and later, see [DecodeText].
+)
+
+func (k ValueKind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindMeasurement:
+		return "measurement"
+	case KindTimestamp:
+		return "timestamp"
+	case KindEventLog:
+		return "event log"
+	case KindLastCapture:
+		return "last capture"
+	case KindLegacyLastCapture:
+		return "legacy last capture"
+	case KindPeakLog:
+		return "peak log"
+	case KindText:
+		return "text"
+	}
+
+	return fmt.Sprintf("ValueKind(%d)", int(k))
+}
+
+// OBISInfo describes a known OBIS code.
+type OBISInfo struct {
+	Description string
+	Unit        string // Expected unit, empty when there is none or it differs per meter.
+	Kind        ValueKind
+}
+
+// registry holds the OBIS codes of DSMR 2.2 up to 5.0, e-MUCS and Smarty. Codes of
+// MBus devices are registered with an "n" channel, as they apply to all channels.
+var registry = map[string]OBISInfo{
+	"1-3:0.2.8":   {"Version information", "", KindString},
+	"0-0:1.0.0":   {"Timestamp", "", KindTimestamp},
+	"0-0:96.1.1":  {"Equipment identifier", "", KindText},
+	"0-0:96.1.4":  {"Version information (e-MUCS)", "", KindString},
+	"0-0:42.0.0":  {"Logical device name (Smarty)", "", KindText},
+	"1-0:1.8.0":   {"Electricity delivered", "kWh", KindMeasurement},
+	"1-0:2.8.0":   {"Electricity returned", "kWh", KindMeasurement},
+	"1-0:3.8.0":   {"Reactive energy imported", "kvarh", KindMeasurement},
+	"1-0:4.8.0":   {"Reactive energy exported", "kvarh", KindMeasurement},
+	"1-0:3.7.0":   {"Actual reactive power imported", "kvar", KindMeasurement},
+	"1-0:4.7.0":   {"Actual reactive power exported", "kvar", KindMeasurement},
+	"1-0:1.8.1":   {"Electricity delivered (tariff 1)", "kWh", KindMeasurement},
+	"1-0:1.8.2":   {"Electricity delivered (tariff 2)", "kWh", KindMeasurement},
+	"1-0:2.8.1":   {"Electricity returned (tariff 1)", "kWh", KindMeasurement},
+	"1-0:2.8.2":   {"Electricity returned (tariff 2)", "kWh", KindMeasurement},
+	"0-0:96.14.0": {"Tariff indicator", "", KindString},
+	"1-0:1.7.0":   {"Actual power delivered", "kW", KindMeasurement},
+	"1-0:2.7.0":   {"Actual power returned", "kW", KindMeasurement},
+	"1-0:1.4.0":   {"Current average demand", "kW", KindMeasurement},
+	"1-0:1.6.0":   {"Maximum demand of the running month", "kW", KindLastCapture},
+	"0-0:98.1.0":  {"Maximum demand of the last 13 months", "kW", KindPeakLog},
+	"0-0:17.0.0":  {"Actual threshold electricity", "", KindMeasurement},
+	"1-0:31.4.0":  {"Current limitation threshold", "A", KindMeasurement},
+	"0-0:96.3.10": {"Actual switch position electricity", "", KindString},
+	"0-0:96.7.21": {"Number of power failures", "", KindString},
+	"0-0:96.7.9":  {"Number of long power failures", "", KindString},
+	"1-0:99.97.0": {"Power failure event log", "s", KindEventLog},
+	"1-0:32.32.0": {"Number of voltage sags (L1)", "", KindString},
+	"1-0:52.32.0": {"Number of voltage sags (L2)", "", KindString},
+	"1-0:72.32.0": {"Number of voltage sags (L3)", "", KindString},
+	"1-0:32.36.0": {"Number of voltage swells (L1)", "", KindString},
+	"1-0:52.36.0": {"Number of voltage swells (L2)", "", KindString},
+	"1-0:72.36.0": {"Number of voltage swells (L3)", "", KindString},
+	"0-0:96.13.1": {"Text message code", "", KindText},
+	"0-0:96.13.0": {"Text message", "", KindText},
+	"1-0:32.7.0":  {"Voltage (L1)", "V", KindMeasurement},
+	"1-0:52.7.0":  {"Voltage (L2)", "V", KindMeasurement},
+	"1-0:72.7.0":  {"Voltage (L3)", "V", KindMeasurement},
+	"1-0:31.7.0":  {"Current (L1)", "A", KindMeasurement},
+	"1-0:51.7.0":  {"Current (L2)", "A", KindMeasurement},
+	"1-0:71.7.0":  {"Current (L3)", "A", KindMeasurement},
+	"1-0:21.7.0":  {"Active power delivered (L1)", "kW", KindMeasurement},
+	"1-0:41.7.0":  {"Active power delivered (L2)", "kW", KindMeasurement},
+	"1-0:61.7.0":  {"Active power delivered (L3)", "kW", KindMeasurement},
+	"1-0:22.7.0":  {"Active power returned (L1)", "kW", KindMeasurement},
+	"1-0:42.7.0":  {"Active power returned (L2)", "kW", KindMeasurement},
+	"1-0:62.7.0":  {"Active power returned (L3)", "kW", KindMeasurement},
+	"0-n:24.1.0":  {"MBus device type", "", KindString},
+	"0-n:96.1.0":  {"MBus equipment identifier", "", KindText},
+	"0-n:24.2.1":  {"MBus last 5-minute value", "", KindLastCapture},
+	"0-n:24.2.3":  {"MBus last value (e-MUCS)", "", KindLastCapture},
+	"0-n:24.3.0":  {"MBus last hourly value", "", KindLegacyLastCapture},
+	"0-n:96.1.1":  {"MBus equipment identifier (e-MUCS)", "", KindText},
+	"0-n:24.4.0":  {"MBus valve position", "", KindString},
+}
+
+// LookupOBIS returns the description of a known OBIS code.
+func LookupOBIS(code OBISCode) (OBISInfo, bool) {
+	if info, ok := registry[code.String()]; ok {
+		return info, true
+	}
+
+	if code.Medium == 0 && code.Channel > 0 && code.Storage == 255 {
+		info, ok := registry[fmt.Sprintf("0-n:%d.%d.%d", code.Indicator, code.Mode, code.Quantity)]
+		return info, ok
+	}
+
+	return OBISInfo{}, false
+}
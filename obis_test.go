@@ -0,0 +1,55 @@
+package dsmr
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestParseOBISCode(t *testing.T) {
+	tests := []struct {
+		code     string
+		fail     string
+		expected OBISCode
+	}{
+		{code: "1-0:1.8.1", expected: OBISCode{1, 0, 1, 8, 1, 255}},
+		{code: "0-1:24.2.1", expected: OBISCode{0, 1, 24, 2, 1, 255}},
+		{code: "1-0:99.97.0*101", expected: OBISCode{1, 0, 99, 97, 0, 101}},
+		{code: "1-0:1.8", fail: "invalid OBIS code \"1-0:1.8\""},
+		{code: "1-0:1.8.256", fail: "invalid OBIS code \"1-0:1.8.256\""},
+		{code: "header", fail: "invalid OBIS code \"header\""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			code, err := ParseOBISCode(test.code)
+			if test.fail != "" {
+				assert.EqualError(t, err, test.fail)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, code)
+				assert.Equal(t, test.code, code.String())
+			}
+		})
+	}
+}
+
+func TestLookupOBIS(t *testing.T) {
+	info, ok := LookupOBIS(OBISCode{1, 0, 1, 8, 1, 255})
+	assert.True(t, ok)
+	assert.Equal(t, OBISInfo{"Electricity delivered (tariff 1)", "kWh", KindMeasurement}, info)
+
+	info, ok = LookupOBIS(OBISCode{0, 2, 24, 2, 1, 255})
+	assert.True(t, ok)
+	assert.Equal(t, KindLastCapture, info.Kind)
+
+	info, ok = LookupOBIS(OBISCode{0, 1, 96, 1, 0, 255})
+	assert.True(t, ok)
+	assert.Equal(t, KindText, info.Kind)
+
+	_, ok = LookupOBIS(OBISCode{1, 0, 1, 8, 1, 1})
+	assert.False(t, ok)
+
+	_, ok = LookupOBIS(OBISCode{0, 0, 24, 2, 1, 255})
+	assert.False(t, ok)
+}
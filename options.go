@@ -1,7 +1,13 @@
 package dsmr
 
+import "fmt"
+
 type parseOptions struct {
 	verifyChecksum bool
+	lenient        bool
+	fastParse      bool
+	decryptionKey  []byte
+	aad            []byte
 }
 
 type Option func(opts *parseOptions) error
@@ -12,3 +18,56 @@ func VerifyChecksum(v bool) Option {
 		return nil
 	}
 }
+
+// Lenient parses the lines of objects that cannot be parsed as objects with a
+// [RawObject] value, instead of failing the whole telegram. The errors of these
+// lines are returned in an [ErrorList], together with the telegram. A checksum
+// that does not match is added to the list, or returned on its own when all
+// lines could be parsed.
+func Lenient(v bool) Option {
+	return func(opts *parseOptions) error {
+		opts.lenient = v
+		return nil
+	}
+}
+
+// FastParse parses telegrams with a hand-written parser, which is a lot faster
+// than the grammar. Telegrams it cannot handle, like invalid ones, are still
+// parsed by the grammar, so the result is the same either way. It still
+// allocates the nodes and numbers of the telegram, use a [View] to parse
+// telegrams without allocating.
+func FastParse(v bool) Option {
+	return func(opts *parseOptions) error {
+		opts.fastParse = v
+		return nil
+	}
+}
+
+// DecryptionKey decrypts telegrams that are sent in encrypted frames, like by
+// Luxembourg Smarty meters, with the encryption key and additional
+// authentication key of the meter. See [Decrypt].
+func DecryptionKey(key, aad []byte) Option {
+	return func(opts *parseOptions) error {
+		if len(key) != 16 {
+			return fmt.Errorf("invalid decryption key of %d bytes", len(key))
+		}
+
+		opts.decryptionKey = key
+		opts.aad = aad
+		return nil
+	}
+}
+
+func newParseOptions(options []Option) (*parseOptions, error) {
+	opts := &parseOptions{
+		verifyChecksum: true,
+	}
+
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,6 @@
+//go:build linux && !ppc64 && !ppc64le
+
+package p1
+
+// cbaud masks the baud rate bits of the control flags.
+const cbaud = 0x100f
@@ -0,0 +1,112 @@
+// Package p1 reads telegrams from the P1 port of a smart meter, either over a
+// serial connection or over TCP with a [Client]. An opened [Port] is a plain
+// io.Reader, so telegrams are read with a dsmr.Reader:
+//
+//	port, err := p1.Open("/dev/ttyUSB0", p1.DSMR4)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer port.Close()
+//
+//	r := dsmr.NewReader(port)
+//	telegram, err := r.Next()
+package p1
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/robinvdvleuten/dsmr"
+)
+
+// Parity is the parity bit setting of a serial line.
+type Parity int
+
+const (
+	ParityNone Parity = iota
+	ParityEven
+	ParityOdd
+)
+
+// Config holds the line settings of a serial port.
+type Config struct {
+	BaudRate int
+	DataBits int
+	Parity   Parity
+	StopBits int
+}
+
+var (
+	// DSMR22 holds the line settings of DSMR 2.2 and 3.0 meters (9600 7E1).
+	DSMR22 = Config{BaudRate: 9600, DataBits: 7, Parity: ParityEven, StopBits: 1}
+
+	// DSMR4 holds the line settings of DSMR 4.x and 5.x meters (115200 8N1).
+	DSMR4 = Config{BaudRate: 115200, DataBits: 8, Parity: ParityNone, StopBits: 1}
+)
+
+// Presets are the line settings tried by [Detect], in order.
+var Presets = []Config{DSMR4, DSMR22}
+
+// ErrNotDetected is returned by [Detect] when none of the presets resulted in
+// a telegram being read.
+var ErrNotDetected = errors.New("p1: cannot detect line settings")
+
+// Detect opens the serial device and tries each of the [Presets] until a
+// telegram is read within the given timeout. It returns the port configured
+// with the detected settings.
+func Detect(name string, timeout time.Duration, options ...dsmr.Option) (*Port, Config, error) {
+	port, err := Open(name, Presets[0])
+	if err != nil {
+		return nil, Config{}, err
+	}
+
+	for _, cfg := range Presets {
+		if err := port.Configure(cfg); err != nil {
+			port.Close()
+			return nil, Config{}, err
+		}
+
+		ok, err := port.detect(timeout, options)
+		if err != nil {
+			port.Close()
+			return nil, Config{}, err
+		}
+
+		if ok {
+			return port, cfg, nil
+		}
+	}
+
+	port.Close()
+	return nil, Config{}, ErrNotDetected
+}
+
+// detect reports whether a telegram can be read with the current settings.
+func (p *Port) detect(timeout time.Duration, options []dsmr.Option) (bool, error) {
+	if err := p.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return false, err
+	}
+	defer p.SetReadDeadline(time.Time{})
+
+	// With wrong settings we read garbage, which is skipped or fails to parse.
+	r := dsmr.NewReader(p, options...)
+
+	for {
+		_, err := r.Next()
+		if err == nil {
+			return true, nil
+		}
+
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return false, nil
+		}
+
+		// Anything but a failure to read from the port means we read garbage.
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) || err == io.EOF || err == io.ErrUnexpectedEOF {
+			return false, err
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package p1
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var baudRates = map[int]uint32{
+	1200:   syscall.B1200,
+	2400:   syscall.B2400,
+	4800:   syscall.B4800,
+	9600:   syscall.B9600,
+	19200:  syscall.B19200,
+	38400:  syscall.B38400,
+	57600:  syscall.B57600,
+	115200: syscall.B115200,
+}
+
+// Port is an opened serial port.
+type Port struct {
+	f *os.File
+}
+
+// Open opens the serial device with the given line settings.
+func Open(name string, cfg Config) (*Port, error) {
+	// Opening in non-blocking mode lets the runtime poll the device, which
+	// makes read deadlines work.
+	fd, err := syscall.Open(name, syscall.O_RDWR|syscall.O_NOCTTY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	p := &Port{f: os.NewFile(uintptr(fd), name)}
+
+	if err := p.Configure(cfg); err != nil {
+		p.f.Close()
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Configure changes the line settings of the port.
+func (p *Port) Configure(cfg Config) error {
+	baud, ok := baudRates[cfg.BaudRate]
+	if !ok {
+		return fmt.Errorf("p1: unsupported baud rate %d", cfg.BaudRate)
+	}
+
+	var cflag uint32 = syscall.CREAD | syscall.CLOCAL | baud
+
+	switch cfg.DataBits {
+	case 7:
+		cflag |= syscall.CS7
+	case 8:
+		cflag |= syscall.CS8
+	default:
+		return fmt.Errorf("p1: unsupported number of data bits %d", cfg.DataBits)
+	}
+
+	switch cfg.Parity {
+	case ParityNone:
+	case ParityEven:
+		cflag |= syscall.PARENB
+	case ParityOdd:
+		cflag |= syscall.PARENB | syscall.PARODD
+	default:
+		return fmt.Errorf("p1: unsupported parity %d", cfg.Parity)
+	}
+
+	switch cfg.StopBits {
+	case 1:
+	case 2:
+		cflag |= syscall.CSTOPB
+	default:
+		return fmt.Errorf("p1: unsupported number of stop bits %d", cfg.StopBits)
+	}
+
+	var t syscall.Termios
+	if err := p.ioctl(syscall.TCGETS, &t); err != nil {
+		return err
+	}
+
+	// Raw mode, so bytes are passed as is and reads return as soon as there is data.
+	t.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	t.Oflag &^= syscall.OPOST
+	t.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	t.Cflag &^= cbaud | syscall.CSIZE | syscall.PARENB | syscall.PARODD | syscall.CSTOPB
+	// The baud rate bits of the control flags set both speeds, as not all
+	// architectures have separate speed fields.
+	t.Cflag |= cflag
+	t.Cc[syscall.VMIN] = 1
+	t.Cc[syscall.VTIME] = 0
+
+	return p.ioctl(syscall.TCSETS, &t)
+}
+
+func (p *Port) ioctl(req uintptr, t *syscall.Termios) error {
+	rc, err := p.f.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var errno syscall.Errno
+	err = rc.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(unsafe.Pointer(t)))
+	})
+	if err != nil {
+		return err
+	}
+
+	if errno != 0 {
+		return &os.PathError{Op: "ioctl", Path: p.f.Name(), Err: errno}
+	}
+
+	return nil
+}
+
+// Read reads data received on the port.
+func (p *Port) Read(b []byte) (int, error) {
+	return p.f.Read(b)
+}
+
+// Write sends data on the port.
+func (p *Port) Write(b []byte) (int, error) {
+	return p.f.Write(b)
+}
+
+// SetReadDeadline sets the deadline for reads, where a zero value means reads
+// do not time out.
+func (p *Port) SetReadDeadline(t time.Time) error {
+	return p.f.SetReadDeadline(t)
+}
+
+// Close closes the port.
+func (p *Port) Close() error {
+	return p.f.Close()
+}
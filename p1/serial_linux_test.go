@@ -0,0 +1,149 @@
+package p1
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/robinvdvleuten/dsmr"
+)
+
+// openPTY opens a pseudo terminal pair, returning its master and the name of
+// its slave device.
+func openPTY(t *testing.T) (*os.File, string) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("cannot open pseudo terminal: %s", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	var unlock int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); errno != 0 {
+		t.Fatal(errno)
+	}
+
+	var n uint32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); errno != 0 {
+		t.Fatal(errno)
+	}
+
+	return master, fmt.Sprintf("/dev/pts/%d", n)
+}
+
+func TestOpen(t *testing.T) {
+	master, name := openPTY(t)
+
+	port, err := Open(name, DSMR22)
+	assert.NoError(t, err)
+	defer port.Close()
+
+	_, err = master.WriteString("garbage" + telegram)
+	assert.NoError(t, err)
+
+	r := dsmr.NewReader(port)
+	v, err := r.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "75B7", v.Footer.Value)
+}
+
+func TestOpenInvalidConfig(t *testing.T) {
+	_, name := openPTY(t)
+
+	_, err := Open(name, Config{BaudRate: 1234, DataBits: 8, StopBits: 1})
+	assert.EqualError(t, err, "p1: unsupported baud rate 1234")
+
+	_, err = Open("/dev/does-not-exist", DSMR4)
+	assert.IsError(t, err, os.ErrNotExist)
+}
+
+func TestReadDeadline(t *testing.T) {
+	_, name := openPTY(t)
+
+	port, err := Open(name, DSMR4)
+	assert.NoError(t, err)
+	defer port.Close()
+
+	err = port.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	assert.NoError(t, err)
+
+	_, err = port.Read(make([]byte, 1))
+	assert.IsError(t, err, os.ErrDeadlineExceeded)
+}
+
+func TestDetect(t *testing.T) {
+	master, name := openPTY(t)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+				master.WriteString(telegram)
+			}
+		}
+	}()
+
+	port, cfg, err := Detect(name, time.Second)
+	assert.NoError(t, err)
+	defer port.Close()
+
+	assert.Equal(t, DSMR4, cfg)
+}
+
+func TestDetectFallback(t *testing.T) {
+	master, name := openPTY(t)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	// The settings of the slave are only seen through a descriptor of its own.
+	slave, err := os.OpenFile(name, os.O_RDONLY|syscall.O_NOCTTY|syscall.O_NONBLOCK, 0)
+	assert.NoError(t, err)
+	defer slave.Close()
+
+	// Like a DSMR 2.2 meter, which only reads as garbage with other settings.
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+				var tio syscall.Termios
+				if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, slave.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&tio))); errno != 0 {
+					return
+				}
+
+				// Pseudo terminals ignore the data bits and parity.
+				if tio.Cflag&cbaud == syscall.B9600 {
+					master.WriteString(telegram)
+				} else {
+					master.WriteString("/\x86\xf3\x1c\r\n0-0:\x00(\r\n!\xe7\r\n")
+				}
+			}
+		}
+	}()
+
+	port, cfg, err := Detect(name, 100*time.Millisecond)
+	assert.NoError(t, err)
+	defer port.Close()
+
+	assert.Equal(t, DSMR22, cfg)
+}
+
+func TestDetectTimeout(t *testing.T) {
+	master, name := openPTY(t)
+
+	_, err := master.WriteString("garbage without telegrams\r\n")
+	assert.NoError(t, err)
+
+	_, _, err = Detect(name, 50*time.Millisecond)
+	assert.Equal(t, ErrNotDetected, err)
+}
@@ -0,0 +1,34 @@
+//go:build !linux
+
+package p1
+
+import (
+	"errors"
+	"time"
+)
+
+var errUnsupported = errors.New("p1: serial ports are not supported on this platform")
+
+// Port is an opened serial port.
+type Port struct{}
+
+// Open opens the serial device with the given line settings.
+func Open(name string, cfg Config) (*Port, error) {
+	return nil, errUnsupported
+}
+
+// Configure changes the line settings of the port.
+func (p *Port) Configure(cfg Config) error { return errUnsupported }
+
+// Read reads data received on the port.
+func (p *Port) Read(b []byte) (int, error) { return 0, errUnsupported }
+
+// Write sends data on the port.
+func (p *Port) Write(b []byte) (int, error) { return 0, errUnsupported }
+
+// SetReadDeadline sets the deadline for reads, where a zero value means reads
+// do not time out.
+func (p *Port) SetReadDeadline(t time.Time) error { return errUnsupported }
+
+// Close closes the port.
+func (p *Port) Close() error { return errUnsupported }
@@ -0,0 +1,283 @@
+package p1
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/robinvdvleuten/dsmr"
+)
+
+// maxConnStats is the number of connections of which a [Client] keeps statistics.
+const maxConnStats = 16
+
+// Default backoffs of a [Client], used when its fields are zero.
+const (
+	defaultMinBackoff = time.Second
+	defaultMaxBackoff = time.Minute
+)
+
+// ConnStats holds statistics of a single connection of a [Client].
+type ConnStats struct {
+	Connected    time.Time
+	Disconnected time.Time // Zero while the connection is open.
+	Bytes        int64     // Number of bytes received.
+	Telegrams    int       // Number of telegrams read.
+	Errors       int       // Number of telegrams that could not be parsed.
+	Err          error     // Reason the connection was closed.
+}
+
+// Client reads telegrams from a P1 port exposed on a TCP port, like by ser2net
+// or network P1 dongles. It reconnects whenever the connection drops or no data
+// is received in time.
+type Client struct {
+	Addr    string
+	Options []dsmr.Option
+
+	// Timeout limits connecting and the time to read a telegram.
+	Timeout time.Duration
+
+	// MinBackoff and MaxBackoff bound the time to wait before reconnecting,
+	// which doubles with every failed attempt. They default to a second and a
+	// minute when zero.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	mu      sync.Mutex
+	conn    net.Conn
+	r       *dsmr.Reader
+	backoff time.Duration
+	stats   []ConnStats
+	closed  bool
+}
+
+// Dial returns a client reading telegrams from the given address, which
+// connects on the first call to [Client.Next].
+func Dial(addr string, options ...dsmr.Option) *Client {
+	return &Client{
+		Addr:       addr,
+		Options:    options,
+		Timeout:    30 * time.Second,
+		MinBackoff: defaultMinBackoff,
+		MaxBackoff: defaultMaxBackoff,
+	}
+}
+
+// Next reads the next telegram, connecting or reconnecting when needed. As with
+// a dsmr.Reader, a telegram that cannot be parsed is returned as an error
+// without affecting the next call.
+//
+// Next gives up when ctx is done, or returns the error of connecting once the
+// time to wait before reconnecting has grown to MaxBackoff. The next call
+// tries again.
+func (c *Client) Next(ctx context.Context) (*dsmr.Telegram, error) {
+	for {
+		if err := c.connect(ctx); err != nil {
+			return nil, err
+		}
+
+		t, err := c.read(ctx)
+		if err == nil || !isReadError(err) {
+			c.record(func(s *ConnStats) {
+				if err == nil {
+					s.Telegrams++
+				} else {
+					s.Errors++
+				}
+			})
+
+			return t, err
+		}
+
+		c.disconnect(err)
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Stats returns statistics of the most recent connections, oldest first.
+func (c *Client) Stats() []ConnStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]ConnStats(nil), c.stats...)
+}
+
+// Close closes the current connection and stops the client from reconnecting.
+func (c *Client) Close() error {
+	c.disconnect(net.ErrClosed)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	return nil
+}
+
+func (c *Client) connect(ctx context.Context) error {
+	c.mu.Lock()
+	conn, closed := c.conn, c.closed
+	c.mu.Unlock()
+
+	if closed {
+		return net.ErrClosed
+	} else if conn != nil {
+		return nil
+	}
+
+	minBackoff, maxBackoff := c.backoffs()
+
+	for {
+		c.mu.Lock()
+		backoff := c.backoff
+		c.mu.Unlock()
+
+		if backoff > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+
+		d := net.Dialer{Timeout: c.Timeout}
+		conn, err := d.DialContext(ctx, "tcp", c.Addr)
+
+		if backoff *= 2; backoff < minBackoff {
+			backoff = minBackoff
+		} else if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+
+		c.mu.Lock()
+		c.backoff = backoff
+		c.mu.Unlock()
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			// Keep trying silently only until the backoff is at its maximum.
+			if backoff == maxBackoff {
+				return err
+			}
+
+			continue
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.conn = conn
+		c.r = dsmr.NewReader(&countingReader{c: c, r: conn}, c.Options...)
+		c.stats = append(c.stats, ConnStats{Connected: time.Now()})
+
+		if len(c.stats) > maxConnStats {
+			c.stats = c.stats[1:]
+		}
+
+		return nil
+	}
+}
+
+// backoffs returns the bounds of the time to wait before reconnecting, with
+// the defaults for fields that are zero.
+func (c *Client) backoffs() (time.Duration, time.Duration) {
+	minBackoff, maxBackoff := c.MinBackoff, c.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = defaultMinBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+
+	return minBackoff, maxBackoff
+}
+
+func (c *Client) read(ctx context.Context) (*dsmr.Telegram, error) {
+	c.mu.Lock()
+	conn, r := c.conn, c.r
+	c.mu.Unlock()
+
+	if c.Timeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(c.Timeout))
+	}
+
+	// Interrupt a blocking read when the context is done.
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetReadDeadline(time.Unix(1, 0))
+		case <-stop:
+		}
+	}()
+
+	t, err := r.Next()
+	if err == nil {
+		// Data flows again, so reconnect swiftly the next time.
+		c.mu.Lock()
+		c.backoff = 0
+		c.mu.Unlock()
+	}
+
+	return t, err
+}
+
+// record updates the statistics of the current connection.
+func (c *Client) record(fn func(s *ConnStats)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.stats) > 0 {
+		fn(&c.stats[len(c.stats)-1])
+	}
+}
+
+func (c *Client) disconnect(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return
+	}
+
+	c.conn.Close()
+	c.conn = nil
+	c.r = nil
+
+	s := &c.stats[len(c.stats)-1]
+	s.Disconnected = time.Now()
+	s.Err = err
+}
+
+// countingReader counts the bytes read from a connection.
+type countingReader struct {
+	c *Client
+	r io.Reader
+}
+
+func (r *countingReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.c.record(func(s *ConnStats) { s.Bytes += int64(n) })
+
+	return n, err
+}
+
+// isReadError reports whether err came from reading the underlying stream,
+// rather than from a single telegram.
+func isReadError(err error) bool {
+	var telegramErr *dsmr.TelegramError
+	return !errors.As(err, &telegramErr)
+}
@@ -0,0 +1,161 @@
+package p1
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+const telegram = "" +
+	"/header\r\n" +
+	"0-0:0.0.0()\r\n" +
+	"!75B7\r\n"
+
+func TestClient(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("garbage" + telegram + "/header\r\n0-0:0.0.0()\r\n!1234\r\n"))
+		conn.Close()
+
+		conn, err = l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte(telegram))
+
+		// Keep the connection open without sending anything.
+		io.Copy(io.Discard, conn)
+	}()
+
+	c := Dial(l.Addr().String())
+	c.MinBackoff = time.Millisecond
+
+	v, err := c.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "75B7", v.Footer.Value)
+
+	_, err = c.Next(context.Background())
+	assert.EqualError(t, err, "unexpected checksum \"75B7\" (expected \"1234\")")
+
+	v, err = c.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "75B7", v.Footer.Value)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = c.Next(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	stats := c.Stats()
+	assert.Equal(t, 2, len(stats))
+	assert.Equal(t, 1, stats[0].Telegrams)
+	assert.Equal(t, 1, stats[0].Errors)
+	assert.Equal(t, int64(len("garbage"+telegram)+len(telegram)), stats[0].Bytes)
+	assert.Equal(t, io.EOF, stats[0].Err)
+	assert.False(t, stats[0].Disconnected.IsZero())
+	assert.Equal(t, 1, stats[1].Telegrams)
+	assert.Equal(t, int64(len(telegram)), stats[1].Bytes)
+
+	err = c.Close()
+	assert.NoError(t, err)
+
+	_, err = c.Next(context.Background())
+	assert.Equal(t, net.ErrClosed, err)
+}
+
+func TestClientTimeout(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+
+	accepted := make(chan struct{}, 2)
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- struct{}{}
+
+			// The first connection stays silent, the second one does not.
+			if len(accepted) == 2 {
+				conn.Write([]byte(telegram))
+			}
+			defer conn.Close()
+		}
+	}()
+
+	c := Dial(l.Addr().String())
+	c.Timeout = 20 * time.Millisecond
+	c.MinBackoff = time.Millisecond
+	defer c.Close()
+
+	v, err := c.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "75B7", v.Footer.Value)
+
+	stats := c.Stats()
+	assert.Equal(t, 2, len(stats))
+	assert.IsError(t, stats[0].Err, os.ErrDeadlineExceeded)
+}
+
+func TestClientDialError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+
+	c := Dial(addr)
+	c.MinBackoff = time.Millisecond
+	c.MaxBackoff = 4 * time.Millisecond
+	defer c.Close()
+
+	_, err = c.Next(context.Background())
+
+	var opErr *net.OpError
+	assert.True(t, errors.As(err, &opErr))
+	assert.Equal(t, "dial", opErr.Op)
+	assert.Equal(t, 0, len(c.Stats()))
+}
+
+func TestClientDefaultBackoff(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+
+	c := &Client{Addr: addr}
+	defer c.Close()
+
+	minBackoff, maxBackoff := c.backoffs()
+	assert.Equal(t, time.Second, minBackoff)
+	assert.Equal(t, time.Minute, maxBackoff)
+
+	// The client waits before reconnecting, instead of failing right away.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = c.Next(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	c.MinBackoff = 2 * time.Minute
+	minBackoff, maxBackoff = c.backoffs()
+	assert.Equal(t, 2*time.Minute, minBackoff)
+	assert.Equal(t, 2*time.Minute, maxBackoff)
+}
@@ -2,6 +2,8 @@ package dsmr
 
 import (
 	"math/big"
+	"regexp"
+	"strings"
 
 	"github.com/alecthomas/participle/v2"
 	"github.com/alecthomas/participle/v2/lexer"
@@ -32,12 +34,14 @@ type Telegram struct {
 func (t *Telegram) Position() lexer.Position { return t.Pos }
 
 func (t *Telegram) children() (children []Node) {
-	children = append(children, t.Header, t.Footer)
+	children = append(children, t.Header)
 
 	for _, obj := range t.Data {
 		children = append(children, obj)
 	}
 
+	children = append(children, t.Footer)
+
 	return
 }
 
@@ -56,7 +60,7 @@ func (h *Header) children() []Node         { return nil }
 type Footer struct {
 	Pos lexer.Position `parser:""`
 
-	Value string `parser:"'!' @~EOL? (?=EOL)"`
+	Value string `parser:"'!' @~EOL* (?=EOL)"`
 }
 
 var _ Entry = &Footer{}
@@ -76,7 +80,16 @@ type Object struct {
 
 var _ Entry = &Object{}
 
-func (o *Object) Key() string              { return o.OBIS.Value }
+// Key returns the OBIS code of the object, or an empty string when the object
+// is a raw line without one.
+func (o *Object) Key() string {
+	if o.OBIS == nil {
+		return ""
+	}
+
+	return o.OBIS.Value
+}
+
 func (o *Object) Position() lexer.Position { return o.Pos }
 func (o *Object) children() []Node         { return []Node{o.OBIS, o.Value} }
 
@@ -91,7 +104,7 @@ type Value interface {
 type EventLog struct {
 	Pos lexer.Position `parser:""`
 
-	Count *Number  `parser:"@@ ')'"`
+	Count *Number  `parser:"@@ ')' (?= '(' OBIS ')' ( EOL | '(' Timestamp ))"`
 	OBIS  *OBIS    `parser:"'(' @@ ( ')' (?='(') )?"`
 	Value []*Event `parser:"@@*"`
 }
@@ -125,12 +138,54 @@ func (e *Event) value()                      {}
 func (e *Event) Position() lexer.Position    { return e.Pos }
 func (e *Event) children() (children []Node) { return []Node{e.Timestamp, e.Value} }
 
+// PeakLog represents the history of monthly peak demands of Belgian e-MUCS meters.
+type PeakLog struct {
+	Pos lexer.Position `parser:""`
+
+	Count *Number `parser:"@@ ')' (?= '(' OBIS ')' '(' OBIS )"`
+	// OBIS codes of the captured objects, which both refer to the maximum demand.
+	OBIS     *OBIS   `parser:"'(' @@ ')'"`
+	PeakOBIS *OBIS   `parser:"'(' @@ ( ')' (?='(') )?"`
+	Value    []*Peak `parser:"@@*"`
+}
+
+var _ Value = &PeakLog{}
+
+func (p *PeakLog) value()                   {}
+func (p *PeakLog) Position() lexer.Position { return p.Pos }
+
+func (p *PeakLog) children() (children []Node) {
+	children = append(children, p.Count, p.OBIS, p.PeakOBIS)
+
+	for _, val := range p.Value {
+		children = append(children, val)
+	}
+
+	return
+}
+
+// Peak represents the peak demand of a month, with the start of the month and
+// the time the peak occurred.
+type Peak struct {
+	Pos lexer.Position `parser:""`
+
+	Month     *Timestamp   `parser:"'(' @@ ')'"`
+	Timestamp *Timestamp   `parser:"'(' @@ ')'"`
+	Value     *Measurement `parser:"'(' @@ ( ')' (?='(') )?"`
+}
+
+var _ Value = &Peak{}
+
+func (p *Peak) value()                      {}
+func (p *Peak) Position() lexer.Position    { return p.Pos }
+func (p *Peak) children() (children []Node) { return []Node{p.Month, p.Timestamp, p.Value} }
+
 // LastCapture represents the last 5-minute capture of a MBus device.
 type LastCapture struct {
 	Pos lexer.Position `parser:""`
 
-	Timestamp *Timestamp   `parser:"@@ ')'"`
-	Value     *Measurement `parser:"'(' @@ ( ?!')' '(' )"`
+	Timestamp *Timestamp   `parser:"(?= Timestamp ('S' | 'W') ')' '(' Number '*' ~(')' | EOL)+ (?! ')' '(')) @@ ')'"`
+	Value     *Measurement `parser:"'(' @@"`
 }
 
 var _ Value = &LastCapture{}
@@ -144,7 +199,7 @@ type LegacyLastCapture struct {
 	Pos lexer.Position `parser:""`
 
 	// We ignore any extraneous values between timestamp and OBIS as specs are unclear about their purpose.
-	Timestamp *String            `parser:"@@ ')' ( '(' ~(')' | OBIS) ')' (?='(') )+ '('"`
+	Timestamp *String            `parser:"(?= ~(')' | EOL)+ ')' ( '(' ~(')' | OBIS) ')' )+ '(' OBIS ')' '(' ~(')' | EOL)+ ')' '(' Number ')') @@ ')' ( '(' ~(')' | OBIS) ')' (?='(') )+ '('"`
 	OBIS      *OBIS              `parser:"@@ ')' '('"`
 	Value     *LegacyMeasurement `parser:"@@"`
 }
@@ -155,6 +210,50 @@ func (l *LegacyLastCapture) value()                      {}
 func (l *LegacyLastCapture) Position() lexer.Position    { return l.Pos }
 func (l *LegacyLastCapture) children() (children []Node) { return []Node{l.Timestamp, l.OBIS, l.Value} }
 
+// Tuple represents a sequence of attributes of an object that does not match
+// any of the other value types, like vendor specific objects. Empty attributes
+// of objects with unknown OBIS codes are kept as strings without a value.
+type Tuple struct {
+	Pos lexer.Position `parser:""`
+
+	Value []Attribute `parser:"(?= ~(')' | EOL)* ')' '(') @@? ( ')' '(' @@? )+"`
+}
+
+var _ Value = &Tuple{}
+
+func (t *Tuple) value()                   {}
+func (t *Tuple) Position() lexer.Position { return t.Pos }
+
+func (t *Tuple) children() (children []Node) {
+	for _, val := range t.Value {
+		children = append(children, val)
+	}
+
+	return
+}
+
+// Attribute represents a single attribute of a [Tuple].
+type Attribute interface {
+	attribute()
+
+	Value
+}
+
+// RawObject represents the value of an object that could not be parsed, as
+// kept by the [Lenient] option.
+type RawObject struct {
+	Pos lexer.Position
+
+	Value string // Raw text of the value, or of the whole line without OBIS code.
+	Err   error  // Error that occurred while parsing the line.
+}
+
+var _ Value = &RawObject{}
+
+func (r *RawObject) value()                   {}
+func (r *RawObject) Position() lexer.Position { return r.Pos }
+func (r *RawObject) children() []Node         { return nil }
+
 // ...
 type OBIS struct {
 	Pos lexer.Position `parser:""`
@@ -162,9 +261,10 @@ type OBIS struct {
 	Value string `parser:"@OBIS"`
 }
 
-var _ Value = &OBIS{}
+var _ Attribute = &OBIS{}
 
 func (o *OBIS) value()                   {}
+func (o *OBIS) attribute()               {}
 func (o *OBIS) Position() lexer.Position { return o.Pos }
 func (o *OBIS) children() []Node         { return nil }
 
@@ -176,9 +276,10 @@ type Measurement struct {
 	Unit  *String `parser:"'*' @@"`
 }
 
-var _ Value = &Measurement{}
+var _ Attribute = &Measurement{}
 
 func (m *Measurement) value()                   {}
+func (m *Measurement) attribute()               {}
 func (m *Measurement) Position() lexer.Position { return m.Pos }
 
 func (m *Measurement) children() (children []Node) {
@@ -212,9 +313,10 @@ type Timestamp struct {
 	DST   bool   `parser:"(@'S' | 'W')"`
 }
 
-var _ Value = &Timestamp{}
+var _ Attribute = &Timestamp{}
 
 func (t *Timestamp) value()                   {}
+func (t *Timestamp) attribute()               {}
 func (t *Timestamp) Position() lexer.Position { return t.Pos }
 func (t *Timestamp) children() []Node         { return nil }
 
@@ -222,11 +324,31 @@ func (t *Timestamp) children() []Node         { return nil }
 type Number struct {
 	Pos lexer.Position `parser:""`
 
-	Value *big.Float `parser:"@Number"`
+	Value *big.Float
+
+	// Text holds the digits as sent by the meter, including their zero
+	// padding. It is empty for numbers that were not parsed.
+	Text string `json:"-"`
 }
 
 var _ Value = &Number{}
 
+// Parse parses a number token into the number. It implements
+// [participle.Parseable], to keep the digits next to the value.
+func (n *Number) Parse(pl *lexer.PeekingLexer) error {
+	tok := pl.Peek()
+	if tok.Type != numberToken {
+		return participle.NextMatch
+	}
+	pl.Next()
+
+	// Number tokens are always valid numbers.
+	n.Value, _ = new(big.Float).SetString(tok.Value)
+	n.Pos, n.Text = tok.Pos, tok.Value
+
+	return nil
+}
+
 func (n *Number) value()                   {}
 func (n *Number) Position() lexer.Position { return n.Pos }
 func (n *Number) children() []Node         { return nil }
@@ -240,26 +362,32 @@ type String struct {
 	Value string `parser:"@(~(')' | EOL)+)"`
 }
 
-var _ Value = &String{}
+var _ Attribute = &String{}
 
 func (s *String) value()                   {}
+func (s *String) attribute()               {}
 func (s *String) Position() lexer.Position { return s.Pos }
 func (s *String) children() []Node         { return nil }
 
+const obisPattern = `\d-\d:\d{1,2}\.\d{1,2}\.\d{1,2}`
+
 var (
 	lex = lexer.MustSimple([]lexer.SimpleRule{
-		{"OBIS", `\d-\d:\d{1,2}\.\d{1,2}\.\d{1,2}`},
-		{"Timestamp", `\d{12}`},
-		{"Number", `\d*\.?\d+`},
-		{"Chars", `[[:alnum:]]+`},
-		{"Punct", `[-_!*.\\/()]`},
-		{"EOL", `\r\n`},
+		{Name: "OBIS", Pattern: obisPattern},
+		{Name: "Timestamp", Pattern: `\d{12}`},
+		{Name: "Number", Pattern: `\d*\.?\d+`},
+		{Name: "Chars", Pattern: `[[:alnum:]]+`},
+		{Name: "Punct", Pattern: `[-_!*.\\/()]`},
+		{Name: "EOL", Pattern: `\r\n`},
 	})
 
+	numberToken = lex.Symbols()["Number"]
+
 	parser = participle.MustBuild[Telegram](
 		participle.Lexer(lex),
 		participle.Elide("EOL"),
-		participle.Union[Value](&EventLog{}, &LastCapture{}, &LegacyLastCapture{}, &Measurement{}, &Timestamp{}, &String{}),
+		participle.Union[Value](&PeakLog{}, &EventLog{}, &LastCapture{}, &LegacyLastCapture{}, &Tuple{}, &Measurement{}, &Timestamp{}, &String{}),
+		participle.Union[Attribute](&Measurement{}, &Timestamp{}, &OBIS{}, &String{}),
 		// We need lookahead to handle legacy last captures correctly.
 		participle.UseLookahead(4),
 	)
@@ -267,20 +395,177 @@ var (
 
 // Parse parses telegram from a string.
 func Parse(str string, options ...Option) (*Telegram, error) {
-	opts := parseOptions{
-		verifyChecksum: true,
+	opts, err := newParseOptions(options)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, option := range options {
-		if err := option(&opts); err != nil {
+	return parse(str, nil, opts)
+}
+
+// parse parses a telegram and verifies its checksum, which is computed from str
+// unless sum holds the checksum computed while reading the telegram already.
+func parse(str string, sum *uint16, opts *parseOptions) (*Telegram, error) {
+	if opts.decryptionKey != nil {
+		b, err := Decrypt([]byte(str), opts.decryptionKey, opts.aad)
+		if err != nil {
 			return nil, err
 		}
+
+		str = string(b)
+	}
+
+	if opts.fastParse {
+		if t, ok := parseFast(str); ok {
+			return t, verifyChecksum(t, str, sum, opts)
+		}
+	}
+
+	if opts.lenient {
+		return parseLenient(str, sum, opts)
 	}
 
+	t, err := parseGrammar(str)
+	if err != nil {
+		return nil, newParseError(str, err)
+	}
+
+	return t, verifyChecksum(t, str, sum, opts)
+}
+
+// parseGrammar parses a telegram with the grammar. The grammar leaves out the
+// empty attributes of tuples, so they are put back as empty strings.
+func parseGrammar(str string) (*Telegram, error) {
 	t, err := parser.ParseString("", str)
 	if err != nil {
 		return nil, err
 	}
 
-	return t, verifyChecksum(t, str, &opts)
+	for _, obj := range t.Data {
+		tuple, ok := obj.Value.(*Tuple)
+		if !ok {
+			continue
+		}
+
+		fillEmptyAttributes(tuple, str)
+
+		if pos, ok := emptyAttribute(tuple); ok && !allowsEmptyAttributes(obj.OBIS.Value) {
+			pos.Offset, pos.Column = pos.Offset-1, pos.Column-1
+			return nil, participle.Errorf(pos, "unexpected token %q", "(")
+		}
+	}
+
+	return t, nil
+}
+
+// allowsEmptyAttributes reports whether an object may hold empty attributes
+// next to others. Only vendor specific objects send these, so the objects of
+// known OBIS codes may not.
+func allowsEmptyAttributes(obis string) bool {
+	code, err := ParseOBISCode(obis)
+	if err != nil {
+		return true
+	}
+
+	_, known := LookupOBIS(code)
+	return !known
+}
+
+// emptyAttribute returns the position of the first empty attribute of a tuple.
+func emptyAttribute(t *Tuple) (lexer.Position, bool) {
+	for _, v := range t.Value {
+		if s, ok := v.(*String); ok && s.Value == "" {
+			return s.Pos, true
+		}
+	}
+
+	return lexer.Position{}, false
+}
+
+// fillEmptyAttributes inserts an empty string for every empty attribute of a
+// tuple, which starts at the first attribute in str.
+func fillEmptyAttributes(t *Tuple, str string) {
+	var (
+		values []Attribute
+		next   int
+	)
+
+	for i := t.Pos.Offset; i < len(str); {
+		end := i + strings.IndexByte(str[i:], ')')
+		if end < i {
+			break
+		}
+
+		if end == i {
+			pos := t.Pos
+			pos.Offset, pos.Column = i, t.Pos.Column+i-t.Pos.Offset
+			values = append(values, &String{Pos: pos})
+		} else if next < len(t.Value) {
+			values = append(values, t.Value[next])
+			next++
+		}
+
+		if end+1 >= len(str) || str[end+1] != '(' {
+			break
+		}
+		i = end + 2
+	}
+
+	t.Value = values
+}
+
+var obisPrefix = regexp.MustCompile("^" + obisPattern)
+
+// newParseError turns an error of the parser into one of the error types of
+// this package, with the line and object the error occurred on.
+func newParseError(str string, err error) error {
+	perr, ok := err.(participle.Error)
+	if !ok {
+		return err
+	}
+
+	pos := perr.Position()
+
+	// Errors at the end of the telegram belong to its last line.
+	offset := pos.Offset
+	if offset >= len(str) {
+		offset = len(strings.TrimSuffix(str, "\r\n"))
+	}
+
+	start := strings.LastIndexByte(str[:offset], '\n') + 1
+	end := strings.Index(str[start:], "\r\n")
+	if end < 0 {
+		end = len(str)
+	} else {
+		end += start
+	}
+
+	line := str[start:end]
+
+	// Values of legacy captures continue on the next line, so look for the
+	// OBIS code of their object on the lines before.
+	obis := ""
+	for i := start; ; {
+		if code := obisPrefix.FindString(str[i:]); code != "" {
+			obis = code
+			break
+		}
+		if i == 0 || str[i] != '(' {
+			break
+		}
+		i = strings.LastIndexByte(str[:i-1], '\n') + 1
+	}
+
+	switch {
+	case !strings.HasPrefix(str, "/"):
+		// Without header it is not a telegram at all.
+	case end == len(str) && !strings.HasSuffix(str, "\r\n"):
+		return &TruncatedTelegramError{Pos: pos, Line: line, OBIS: obis, Err: err}
+	case !strings.Contains(str, "\n!"):
+		return &MissingFooterError{Pos: pos, Line: line, OBIS: obis, Err: err}
+	case obis != "":
+		return &UnknownValueError{Pos: pos, Line: line, OBIS: obis, Err: err}
+	}
+
+	return &SyntaxError{Pos: pos, Line: line, OBIS: obis, Err: err}
 }
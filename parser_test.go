@@ -1,8 +1,11 @@
 package dsmr
 
 import (
+	"errors"
 	"math/big"
+	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/alecthomas/assert/v2"
@@ -284,11 +287,95 @@ func TestParse(t *testing.T) {
 				Footer: footer("8397"),
 			},
 		},
+		{
+			name: "e-MUCS",
+			telegram: "" +
+				"/FLU5\\253769484_A\r\n" +
+				"\r\n" +
+				"0-0:96.1.4(50217)\r\n" +
+				"1-0:1.4.0(02.351*kW)\r\n" +
+				"1-0:1.6.0(200509134558S)(02.589*kW)\r\n" +
+				"0-0:98.1.0(2)(1-0:1.6.0)(1-0:1.6.0)(200501000000S)(200423192538S)(03.695*kW)" +
+				"(200401000000S)(200305122139S)(05.980*kW)\r\n" +
+				"0-0:98.1.0(0)(1-0:1.6.0)(1-0:1.6.0)\r\n" +
+				"0-1:24.2.3(200512134558S)(00112.384*m3)\r\n" +
+				"!\r\n",
+			expected: &Telegram{
+				Header: header("FLU5\\253769484_A"),
+				Data: []*Object{
+					obj("0-0:96.1.4", str("50217")),
+					obj("1-0:1.4.0", mm("02.351", "kW")),
+					obj("1-0:1.6.0", lc(ts("200509134558", true), mm("02.589", "kW"))),
+					obj("0-0:98.1.0",
+						peaks("2", "1-0:1.6.0", "1-0:1.6.0",
+							peak(ts("200501000000", true), ts("200423192538", true), mm("03.695", "kW")),
+							peak(ts("200401000000", true), ts("200305122139", true), mm("05.980", "kW")),
+						),
+					),
+					obj("0-0:98.1.0", peaks("0", "1-0:1.6.0", "1-0:1.6.0")),
+					obj("0-1:24.2.3", lc(ts("200512134558", true), mm("00112.384", "m3"))),
+				},
+				Footer: &Footer{},
+			},
+		},
+		{
+			// Values that start like a last capture, but are not, are only
+			// told apart after more tokens than the parser looks ahead.
+			name: "Lookahead",
+			telegram: "" +
+				"/header\r\n" +
+				"0-0:96.13.0(www.example.com/meter)\r\n" +
+				"0-0:96.50.1(200509134558S)(01.111*kW)(12)\r\n" +
+				"!\r\n",
+			expected: &Telegram{
+				Header: header("header"),
+				Data: []*Object{
+					obj("0-0:96.13.0", str("www.example.com/meter")),
+					obj("0-0:96.50.1", tuple(ts("200509134558", true), mm("01.111", "kW"), str("12"))),
+				},
+				Footer: &Footer{},
+			},
+		},
+		{
+			name: "Tuple",
+			telegram: "" +
+				"/header\r\n" +
+				"1-0:1.6.0(200509134558S)(01.111*kW)\r\n" +
+				"0-0:96.50.1(200509134558S)(200509140000S)(01.111*kW)\r\n" +
+				"0-0:96.50.2(VENDOR)(1-0:1.8.1)(12)\r\n" +
+				"0-0:96.50.3()(VENDOR)()(12)\r\n" +
+				"!\r\n",
+			expected: &Telegram{
+				Header: header("header"),
+				Data: []*Object{
+					obj("1-0:1.6.0", lc(ts("200509134558", true), mm("01.111", "kW"))),
+					obj("0-0:96.50.1", tuple(ts("200509134558", true), ts("200509140000", true), mm("01.111", "kW"))),
+					obj("0-0:96.50.2", tuple(str("VENDOR"), obis("1-0:1.8.1"), str("12"))),
+					obj("0-0:96.50.3", tuple(str(""), str("VENDOR"), str(""), str("12"))),
+				},
+				Footer: &Footer{},
+			},
+		},
 		{
 			name:     "InvalidTelegram",
 			telegram: "invalid_telegram",
 			fail:     "1:1: unexpected token \"invalid\"",
 		},
+		{
+			name:     "TruncatedTelegram",
+			telegram: "/header\r\n1-0:1.8.1(0001",
+			fail:     "2:1: truncated telegram: unexpected token \"1-0:1.8.1\" (expected Footer)",
+		},
+		{
+			name:     "MissingFooter",
+			telegram: "/header\r\n1-0:1.8.1(0001*kWh)\r\n",
+			fail:     "3:1: missing footer",
+		},
+		{
+			name:     "UnknownValue",
+			telegram: "/header\r\n1-0:1.8.1(0001*kWh)()\r\n!\r\n",
+			fail:     "2:20: unknown value of 1-0:1.8.1: unexpected token \"(\"",
+		},
 	}
 
 	for _, test := range tests {
@@ -308,6 +395,181 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseErrors(t *testing.T) {
+	_, err := Parse("invalid_telegram")
+	var syntaxErr *SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, "invalid_telegram", syntaxErr.Line)
+
+	_, err = Parse("/header\r\n0-1:24.3.0(161107190000)(00)(60)(1)(0-1:24.2.1)(m3)\r\n(00001.001")
+	var truncatedErr *TruncatedTelegramError
+	assert.True(t, errors.As(err, &truncatedErr))
+	assert.Equal(t, "0-1:24.3.0", truncatedErr.OBIS)
+
+	_, err = Parse("/header\r\n1-0:1.8.1(0001*kWh)\r\n")
+	var footerErr *MissingFooterError
+	assert.True(t, errors.As(err, &footerErr))
+	assert.Equal(t, "1-0:1.8.1(0001*kWh)", footerErr.Line)
+	assert.Equal(t, 3, footerErr.Pos.Line)
+
+	_, err = Parse("/header\r\n1-0:1.8.1(00 1)\r\n!\r\n")
+	var valueErr *UnknownValueError
+	assert.True(t, errors.As(err, &valueErr))
+	assert.Equal(t, "1-0:1.8.1", valueErr.OBIS)
+	assert.Equal(t, "1-0:1.8.1(00 1)", valueErr.Line)
+	assert.Equal(t, 13, valueErr.Pos.Column)
+}
+
+func TestParseLenient(t *testing.T) {
+	telegram := "" +
+		"/header\r\n" +
+		"1-0:1.8.1(00 1)\r\n" +
+		"1-0:1.8.2(0002*kWh)\r\n" +
+		"garbage\r\n" +
+		"0-1:24.3.0(161107190000)(00)(60)(1)(0-1:24.2.1)(m3)\r\n" +
+		"(00 1)\r\n" +
+		"1-0:2.8.1(0003*kWh)\r\n" +
+		"!\r\n"
+
+	_, err := Parse(telegram)
+	assert.Error(t, err)
+
+	parsed, err := Parse(telegram, Lenient(true))
+	assert.EqualError(t, err, "2:13: unknown value of 1-0:1.8.1: lexer: invalid input text \" 1)\\r\\n1-0:1.8.2(0...\" (and 2 more errors)")
+
+	var errs ErrorList
+	assert.True(t, errors.As(err, &errs))
+	assert.Equal(t, 3, len(errs))
+
+	var syntaxErr *SyntaxError
+	assert.True(t, errors.As(errs[1], &syntaxErr))
+	assert.Equal(t, "garbage", syntaxErr.Line)
+	assert.Equal(t, 4, syntaxErr.Pos.Line)
+	assert.Equal(t, strings.Index(telegram, "garbage"), syntaxErr.Pos.Offset)
+
+	keys := []string{}
+	for _, obj := range parsed.Data {
+		keys = append(keys, obj.Key())
+	}
+	assert.Equal(t, []string{"1-0:1.8.1", "1-0:1.8.2", "", "0-1:24.3.0", "1-0:2.8.1"}, keys)
+
+	raw, ok := parsed.Data[3].Value.(*RawObject)
+	assert.True(t, ok)
+	assert.Equal(t, "(161107190000)(00)(60)(1)(0-1:24.2.1)(m3)\r\n(00 1)", raw.Value)
+	assert.Equal(t, 5, parsed.Data[3].Pos.Line)
+
+	last := parsed.Data[4]
+	assert.Equal(t, 7, last.Pos.Line)
+	assert.Equal(t, strings.Index(telegram, "1-0:2.8.1"), last.Pos.Offset)
+	assert.Equal(t, strings.Index(telegram, "0003"), last.Value.(*Measurement).Value.Pos.Offset)
+
+	reading, err := parsed.Decode()
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, reading.ElectricityDeliveredTariff2)
+	assert.Equal(t, 3.0, reading.ElectricityReturnedTariff1)
+	assert.Equal(t, 0.0, reading.ElectricityDeliveredTariff1)
+
+	encoded, err := parsed.MarshalText()
+	assert.NoError(t, err)
+	assert.Contains(t, string(encoded), "\r\ngarbage\r\n0-1:24.3.0(161107190000)(00)(60)(1)(0-1:24.2.1)(m3)\r\n(00 1)\r\n")
+
+	_, err = Parse("/header\r\n1-0:1.8.1(0001*kWh)\r\n", Lenient(true))
+	var footerErr *MissingFooterError
+	assert.True(t, errors.As(err, &footerErr))
+
+	_, err = Parse("/header\r\n1-0:1.8.1(0001*kWh)\r\n!0000\r\n", Lenient(true))
+	var checksumErr *ChecksumError
+	assert.True(t, errors.As(err, &checksumErr))
+	assert.False(t, errors.As(err, &errs))
+
+	_, err = Parse("/header\r\ngarbage\r\n!0000\r\n", Lenient(true))
+	assert.True(t, errors.As(err, &errs))
+	assert.Equal(t, 2, len(errs))
+	assert.True(t, errors.As(err, &checksumErr))
+}
+
+func TestParseFast(t *testing.T) {
+	telegrams := []string{
+		telegramV22,
+		telegramV42,
+		telegramEMUCS,
+		"/header\r\n0-0:96.50.1(200509134558S)(200509140000S)(01.111*kW)\r\n0-0:96.50.2(VENDOR)(1-0:1.8.1)(12)\r\n!\r\n",
+		"/header\r\n0-0:96.50.3()(VENDOR)()(12)\r\n0-0:96.50.4(1)()\r\n!\r\n",
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		b, err := randomTelegram(r).MarshalText()
+		assert.NoError(t, err)
+		telegrams = append(telegrams, string(b))
+	}
+
+	for _, telegram := range telegrams {
+		expected, err := parseGrammar(telegram)
+		assert.NoError(t, err)
+
+		actual, ok := parseFast(telegram)
+		assert.True(t, ok, telegram)
+		assert.Equal(t, repr.String(expected), repr.String(actual), telegram)
+	}
+
+	_, err := Parse("/header\r\n1-0:1.8.1(00 1)\r\n!\r\n", FastParse(true))
+	var valueErr *UnknownValueError
+	assert.True(t, errors.As(err, &valueErr))
+
+	// Known objects do not hold empty attributes.
+	_, err = Parse("/header\r\n1-0:1.8.1(0001*kWh)()\r\n!\r\n", FastParse(true))
+	assert.EqualError(t, err, "2:20: unknown value of 1-0:1.8.1: unexpected token \"(\"")
+}
+
+func FuzzParseFast(f *testing.F) {
+	for _, telegram := range []string{telegramV22, telegramV42, telegramEMUCS} {
+		f.Add(telegram)
+	}
+
+	f.Fuzz(func(t *testing.T, telegram string) {
+		actual, ok := parseFast(telegram)
+		if !ok {
+			return
+		}
+
+		expected, err := parseGrammar(telegram)
+		assert.NoError(t, err)
+		assert.Equal(t, repr.String(expected), repr.String(actual))
+	})
+}
+
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(telegramV42); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseFast(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(telegramV42, FastParse(true)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseView(b *testing.B) {
+	b.ReportAllocs()
+
+	var v View
+	for i := 0; i < b.N; i++ {
+		if err := v.Parse(telegramV42); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func normalizeTelegram(t *Telegram) *Telegram {
 	if t == nil {
 		return nil
@@ -352,6 +614,14 @@ func event(ts *Timestamp, v string) *Event {
 	return &Event{Timestamp: ts, Value: mm(v, "s")}
 }
 
+func peaks(c string, o string, p string, v ...*Peak) *PeakLog {
+	return &PeakLog{Count: num(c), OBIS: obis(o), PeakOBIS: obis(p), Value: v}
+}
+
+func peak(month *Timestamp, ts *Timestamp, v *Measurement) *Peak {
+	return &Peak{Month: month, Timestamp: ts, Value: v}
+}
+
 func lc(ts *Timestamp, v *Measurement) *LastCapture {
 	return &LastCapture{Timestamp: ts, Value: v}
 }
@@ -360,6 +630,10 @@ func llc(ts *String, o *OBIS, v *LegacyMeasurement) *LegacyLastCapture {
 	return &LegacyLastCapture{Timestamp: ts, OBIS: o, Value: v}
 }
 
+func tuple(v ...Attribute) *Tuple {
+	return &Tuple{Value: v}
+}
+
 func obis(v string) *OBIS {
 	return &OBIS{Value: v}
 }
@@ -380,7 +654,7 @@ func num(v string) *Number {
 	b := &big.Float{}
 	_, _, _ = b.Parse(v, 0)
 
-	return &Number{Value: b}
+	return &Number{Value: b, Text: v}
 }
 
 func str(v string) *String {
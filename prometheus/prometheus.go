@@ -0,0 +1,314 @@
+// Package prometheus exposes the latest telegram read from a meter as metrics
+// in the Prometheus text exposition format. Metric names are the same for all
+// DSMR versions, and values are converted into the units in their names:
+//
+//	e := prometheus.NewExporter()
+//	go e.Read(dsmr.NewReader(port))
+//
+//	http.Handle("/metrics", e)
+//	log.Fatal(http.ListenAndServe(":9111", nil))
+package prometheus
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/robinvdvleuten/dsmr"
+)
+
+// contentType is the content type of the text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Exporter holds the latest telegram read from a meter, and counts the
+// telegrams that could not be read.
+type Exporter struct {
+	mu             sync.Mutex
+	telegram       *dsmr.Telegram
+	telegrams      uint64
+	parseErrors    uint64
+	checksumErrors uint64
+}
+
+// NewExporter returns an exporter without a telegram.
+func NewExporter() *Exporter {
+	return &Exporter{}
+}
+
+// Observe records the result of reading a telegram. The telegram replaces the
+// one exposed, while errors increase the parse or checksum error counters.
+func (e *Exporter) Observe(t *dsmr.Telegram, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var checksumErr *dsmr.ChecksumError
+	switch {
+	case errors.As(err, &checksumErr):
+		e.checksumErrors++
+	case err != nil:
+		e.parseErrors++
+	default:
+		e.telegram = t
+		e.telegrams++
+	}
+}
+
+// Read reads telegrams from r until the end of the stream, observing each of
+// them. Only errors of reading from the stream are returned.
+func (e *Exporter) Read(r *dsmr.Reader) error {
+	for {
+		t, err := r.Next()
+		switch err {
+		case io.EOF:
+			return nil
+		case io.ErrUnexpectedEOF:
+			// The last telegram got cut off.
+			e.Observe(nil, err)
+			return nil
+		}
+
+		var telegramErr *dsmr.TelegramError
+		if err != nil && !errors.As(err, &telegramErr) {
+			return err
+		}
+
+		e.Observe(t, err)
+	}
+}
+
+// ServeHTTP writes the metrics in the text exposition format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	_, _ = e.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the text exposition format to w.
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	e.mu.Lock()
+	m := metrics{}
+	m.add("dsmr_telegrams_total", counter, "Number of telegrams read.", nil, float64(e.telegrams))
+	m.add("dsmr_parse_errors_total", counter, "Number of telegrams that could not be parsed.", nil, float64(e.parseErrors))
+	m.add("dsmr_checksum_errors_total", counter, "Number of telegrams with an invalid checksum.", nil, float64(e.checksumErrors))
+	if e.telegram != nil {
+		m.addTelegram(e.telegram)
+	}
+	e.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	m.write(cw)
+
+	if err := cw.w.Flush(); err != nil {
+		return cw.n, err
+	}
+
+	return cw.n, cw.err
+}
+
+type metricType string
+
+const (
+	counter metricType = "counter"
+	gauge   metricType = "gauge"
+)
+
+type sample struct {
+	labels string
+	value  float64
+}
+
+type metric struct {
+	typ     metricType
+	help    string
+	samples []sample
+}
+
+// metrics holds metrics by their name.
+type metrics map[string]*metric
+
+func (m metrics) add(name string, typ metricType, help string, labels map[string]string, value float64) {
+	mm, ok := m[name]
+	if !ok {
+		mm = &metric{typ: typ, help: help}
+		m[name] = mm
+	}
+
+	mm.samples = append(mm.samples, sample{labels: formatLabels(labels), value: value})
+}
+
+// write writes the metrics ordered by name, and their samples by labels.
+func (m metrics) write(w io.Writer) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		mm := m[name]
+		sort.SliceStable(mm.samples, func(i, j int) bool { return mm.samples[i].labels < mm.samples[j].labels })
+
+		fmt.Fprintf(w, "# HELP %s %s\n", name, mm.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", name, mm.typ)
+		for _, s := range mm.samples {
+			fmt.Fprintf(w, "%s%s %s\n", name, s.labels, strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+	}
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+strconv.Quote(v))
+	}
+	sort.Strings(pairs)
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// register is a metric of a single OBIS code.
+type register struct {
+	name   string
+	typ    metricType
+	help   string
+	unit   dsmr.Unit // Unit the value is converted into, unknown for plain numbers.
+	labels map[string]string
+}
+
+var registers = map[string]register{
+	"1-0:1.8.0":   {"dsmr_electricity_delivered_kwh_total", counter, "Electricity delivered to the client.", dsmr.UnitKWh, map[string]string{"tariff": "0"}},
+	"1-0:1.8.1":   {"dsmr_electricity_delivered_kwh_total", counter, "Electricity delivered to the client.", dsmr.UnitKWh, map[string]string{"tariff": "1"}},
+	"1-0:1.8.2":   {"dsmr_electricity_delivered_kwh_total", counter, "Electricity delivered to the client.", dsmr.UnitKWh, map[string]string{"tariff": "2"}},
+	"1-0:2.8.0":   {"dsmr_electricity_returned_kwh_total", counter, "Electricity returned by the client.", dsmr.UnitKWh, map[string]string{"tariff": "0"}},
+	"1-0:2.8.1":   {"dsmr_electricity_returned_kwh_total", counter, "Electricity returned by the client.", dsmr.UnitKWh, map[string]string{"tariff": "1"}},
+	"1-0:2.8.2":   {"dsmr_electricity_returned_kwh_total", counter, "Electricity returned by the client.", dsmr.UnitKWh, map[string]string{"tariff": "2"}},
+	"0-0:96.14.0": {"dsmr_electricity_tariff", gauge, "Tariff currently in use.", dsmr.UnitUnknown, nil},
+	"1-0:1.7.0":   {"dsmr_power_delivered_kw", gauge, "Actual power delivered to the client.", dsmr.UnitKW, nil},
+	"1-0:2.7.0":   {"dsmr_power_returned_kw", gauge, "Actual power returned by the client.", dsmr.UnitKW, nil},
+	"0-0:96.7.21": {"dsmr_power_failures_total", counter, "Number of power failures.", dsmr.UnitUnknown, nil},
+	"0-0:96.7.9":  {"dsmr_long_power_failures_total", counter, "Number of long power failures.", dsmr.UnitUnknown, nil},
+	"1-0:32.7.0":  {"dsmr_voltage_volts", gauge, "Actual voltage per phase.", dsmr.UnitV, map[string]string{"phase": "l1"}},
+	"1-0:52.7.0":  {"dsmr_voltage_volts", gauge, "Actual voltage per phase.", dsmr.UnitV, map[string]string{"phase": "l2"}},
+	"1-0:72.7.0":  {"dsmr_voltage_volts", gauge, "Actual voltage per phase.", dsmr.UnitV, map[string]string{"phase": "l3"}},
+	"1-0:31.7.0":  {"dsmr_current_amperes", gauge, "Actual current per phase.", dsmr.UnitA, map[string]string{"phase": "l1"}},
+	"1-0:51.7.0":  {"dsmr_current_amperes", gauge, "Actual current per phase.", dsmr.UnitA, map[string]string{"phase": "l2"}},
+	"1-0:71.7.0":  {"dsmr_current_amperes", gauge, "Actual current per phase.", dsmr.UnitA, map[string]string{"phase": "l3"}},
+	"1-0:21.7.0":  {"dsmr_phase_power_delivered_kw", gauge, "Actual power delivered per phase.", dsmr.UnitKW, map[string]string{"phase": "l1"}},
+	"1-0:41.7.0":  {"dsmr_phase_power_delivered_kw", gauge, "Actual power delivered per phase.", dsmr.UnitKW, map[string]string{"phase": "l2"}},
+	"1-0:61.7.0":  {"dsmr_phase_power_delivered_kw", gauge, "Actual power delivered per phase.", dsmr.UnitKW, map[string]string{"phase": "l3"}},
+	"1-0:22.7.0":  {"dsmr_phase_power_returned_kw", gauge, "Actual power returned per phase.", dsmr.UnitKW, map[string]string{"phase": "l1"}},
+	"1-0:42.7.0":  {"dsmr_phase_power_returned_kw", gauge, "Actual power returned per phase.", dsmr.UnitKW, map[string]string{"phase": "l2"}},
+	"1-0:62.7.0":  {"dsmr_phase_power_returned_kw", gauge, "Actual power returned per phase.", dsmr.UnitKW, map[string]string{"phase": "l3"}},
+}
+
+// devices are the metrics of MBus devices by their type.
+var devices = map[dsmr.DeviceType]register{
+	dsmr.DeviceGas:   {"dsmr_gas_delivered_m3_total", counter, "Gas delivered to the client.", dsmr.UnitM3, nil},
+	dsmr.DeviceWater: {"dsmr_water_delivered_m3_total", counter, "Water delivered to the client.", dsmr.UnitM3, nil},
+	dsmr.DeviceHeat:  {"dsmr_heat_delivered_gj_total", counter, "Heat delivered to the client.", dsmr.UnitGJ, nil},
+}
+
+// addTelegram adds the metrics of the objects of a telegram. Objects that
+// cannot be converted, like those with an unexpected unit, are left out.
+func (m metrics) addTelegram(t *dsmr.Telegram) {
+	if obj := find(t, "0-0:1.0.0"); obj != nil {
+		if ts, ok := obj.Value.(*dsmr.Timestamp); ok {
+			if tm, err := ts.Time(); err == nil {
+				m.add("dsmr_telegram_timestamp_seconds", gauge, "Time the latest telegram was sent by the meter.", nil, float64(tm.Unix()))
+			}
+		}
+	}
+
+	for _, obj := range t.Data {
+		reg, ok := registers[obj.Key()]
+		if !ok {
+			continue
+		}
+
+		if value, ok := number(obj.Value, reg.unit); ok {
+			m.add(reg.name, reg.typ, reg.help, reg.labels, value)
+		}
+	}
+
+	// Devices are returned even when some of their objects cannot be decoded.
+	mbus, _ := t.MBusDevices()
+	for _, device := range mbus {
+		reg, ok := devices[device.Type]
+		if !ok || device.Timestamp.IsZero() {
+			continue
+		}
+
+		from, err := dsmr.ParseUnit(device.Unit)
+		if err != nil {
+			continue
+		}
+
+		value, err := dsmr.Convert(big.NewFloat(device.Value), from, reg.unit)
+		if err != nil {
+			continue
+		}
+
+		f, _ := value.Float64()
+		m.add(reg.name, reg.typ, reg.help, map[string]string{"channel": strconv.Itoa(device.Channel)}, f)
+	}
+}
+
+// number returns the value of a measurement in unit, or of a plain number.
+func number(v dsmr.Value, unit dsmr.Unit) (float64, bool) {
+	switch v := v.(type) {
+	case *dsmr.Measurement:
+		value, err := v.In(unit)
+		if err != nil {
+			return 0, false
+		}
+		f, _ := value.Float64()
+		return f, true
+	case *dsmr.String:
+		if unit != dsmr.UnitUnknown {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(v.Value, 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+// find returns the first object with the given OBIS code.
+func find(t *dsmr.Telegram, key string) *dsmr.Object {
+	for _, obj := range t.Data {
+		if obj.Key() == key {
+			return obj
+		}
+	}
+
+	return nil
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n, err := w.w.Write(b)
+	w.n += int64(n)
+	w.err = err
+
+	return n, err
+}
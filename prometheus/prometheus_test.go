@@ -0,0 +1,162 @@
+package prometheus
+
+import (
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/robinvdvleuten/dsmr"
+)
+
+const telegram = "" +
+	"/KFM5KAIFA-METER\r\n" +
+	"\r\n" +
+	"1-3:0.2.8(42)\r\n" +
+	"0-0:1.0.0(161113205757W)\r\n" +
+	"1-0:1.8.1(001581.123*kWh)\r\n" +
+	"1-0:1.8.2(001435.706*kWh)\r\n" +
+	"1-0:2.8.1(000000.000*kWh)\r\n" +
+	"1-0:2.8.2(000000.000*kWh)\r\n" +
+	"0-0:96.14.0(0002)\r\n" +
+	"1-0:1.7.0(02.027*kW)\r\n" +
+	"1-0:2.7.0(00.000*kW)\r\n" +
+	"0-0:96.7.21(00015)\r\n" +
+	"1-0:32.7.0(230.1*V)\r\n" +
+	"1-0:31.7.0(000*A)\r\n" +
+	"1-0:51.7.0(006*A)\r\n" +
+	"1-0:21.7.0(170*W)\r\n" +
+	"0-1:24.1.0(003)\r\n" +
+	"0-1:24.2.1(161129200000W)(00981.443*m3)\r\n" +
+	"0-2:24.1.0(007)\r\n" +
+	"0-2:24.2.1(161129200000W)(00012.5*m3)\r\n" +
+	"!\r\n"
+
+const expected = `# HELP dsmr_checksum_errors_total Number of telegrams with an invalid checksum.
+# TYPE dsmr_checksum_errors_total counter
+dsmr_checksum_errors_total 1
+# HELP dsmr_current_amperes Actual current per phase.
+# TYPE dsmr_current_amperes gauge
+dsmr_current_amperes{phase="l1"} 0
+dsmr_current_amperes{phase="l2"} 6
+# HELP dsmr_electricity_delivered_kwh_total Electricity delivered to the client.
+# TYPE dsmr_electricity_delivered_kwh_total counter
+dsmr_electricity_delivered_kwh_total{tariff="1"} 1581.123
+dsmr_electricity_delivered_kwh_total{tariff="2"} 1435.706
+# HELP dsmr_electricity_returned_kwh_total Electricity returned by the client.
+# TYPE dsmr_electricity_returned_kwh_total counter
+dsmr_electricity_returned_kwh_total{tariff="1"} 0
+dsmr_electricity_returned_kwh_total{tariff="2"} 0
+# HELP dsmr_electricity_tariff Tariff currently in use.
+# TYPE dsmr_electricity_tariff gauge
+dsmr_electricity_tariff 2
+# HELP dsmr_gas_delivered_m3_total Gas delivered to the client.
+# TYPE dsmr_gas_delivered_m3_total counter
+dsmr_gas_delivered_m3_total{channel="1"} 981.443
+# HELP dsmr_parse_errors_total Number of telegrams that could not be parsed.
+# TYPE dsmr_parse_errors_total counter
+dsmr_parse_errors_total 1
+# HELP dsmr_phase_power_delivered_kw Actual power delivered per phase.
+# TYPE dsmr_phase_power_delivered_kw gauge
+dsmr_phase_power_delivered_kw{phase="l1"} 0.17
+# HELP dsmr_power_delivered_kw Actual power delivered to the client.
+# TYPE dsmr_power_delivered_kw gauge
+dsmr_power_delivered_kw 2.027
+# HELP dsmr_power_failures_total Number of power failures.
+# TYPE dsmr_power_failures_total counter
+dsmr_power_failures_total 15
+# HELP dsmr_power_returned_kw Actual power returned by the client.
+# TYPE dsmr_power_returned_kw gauge
+dsmr_power_returned_kw 0
+# HELP dsmr_telegram_timestamp_seconds Time the latest telegram was sent by the meter.
+# TYPE dsmr_telegram_timestamp_seconds gauge
+dsmr_telegram_timestamp_seconds 1.479067077e+09
+# HELP dsmr_telegrams_total Number of telegrams read.
+# TYPE dsmr_telegrams_total counter
+dsmr_telegrams_total 1
+# HELP dsmr_voltage_volts Actual voltage per phase.
+# TYPE dsmr_voltage_volts gauge
+dsmr_voltage_volts{phase="l1"} 230.1
+# HELP dsmr_water_delivered_m3_total Water delivered to the client.
+# TYPE dsmr_water_delivered_m3_total counter
+dsmr_water_delivered_m3_total{channel="2"} 12.5
+`
+
+func TestExporter(t *testing.T) {
+	stream := "" +
+		"/header\r\n1-0:1.8.1(0001*kWh)()\r\n!\r\n" +
+		"/header\r\n0-0:0.0.0()\r\n!0000\r\n" +
+		telegram
+
+	e := NewExporter()
+	assert.NoError(t, e.Read(dsmr.NewReader(strings.NewReader(stream))))
+
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, expected, string(body))
+}
+
+func TestExporterEncrypted(t *testing.T) {
+	// Test case 4 of the GCM specification, with its tag truncated to 12 bytes.
+	frame, err := hex.DecodeString("" +
+		"db08cafebabefacedbad82004dfedecaf888" +
+		"42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e" +
+		"21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091" +
+		"5bc94fbc3221a5db94fae95a")
+	assert.NoError(t, err)
+
+	// Frames that fail to authenticate or have an unsupported security
+	// control are single telegrams that cannot be read.
+	invalid := append([]byte(nil), frame...)
+	invalid[13] = 0x00
+
+	key, aad := make([]byte, 16), make([]byte, 16)
+	r := dsmr.NewReader(strings.NewReader(string(frame)+string(invalid)), dsmr.DecryptionKey(key, aad))
+
+	e := NewExporter()
+	assert.NoError(t, e.Read(r))
+
+	var metrics strings.Builder
+	_, err = e.WriteTo(&metrics)
+	assert.NoError(t, err)
+	assert.Contains(t, metrics.String(), "dsmr_parse_errors_total 2\n")
+}
+
+func TestExporterEmpty(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewExporter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ""+
+		"# HELP dsmr_checksum_errors_total Number of telegrams with an invalid checksum.\n"+
+		"# TYPE dsmr_checksum_errors_total counter\n"+
+		"dsmr_checksum_errors_total 0\n"+
+		"# HELP dsmr_parse_errors_total Number of telegrams that could not be parsed.\n"+
+		"# TYPE dsmr_parse_errors_total counter\n"+
+		"dsmr_parse_errors_total 0\n"+
+		"# HELP dsmr_telegrams_total Number of telegrams read.\n"+
+		"# TYPE dsmr_telegrams_total counter\n"+
+		"dsmr_telegrams_total 0\n", rec.Body.String())
+}
+
+func TestExporterReadError(t *testing.T) {
+	e := NewExporter()
+	err := e.Read(dsmr.NewReader(io.MultiReader(strings.NewReader("/header\r\n"), errReader{})))
+	assert.Equal(t, io.ErrClosedPipe, err)
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, io.ErrClosedPipe }
@@ -0,0 +1,241 @@
+package dsmr
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// maxTelegramSize is the maximum number of bytes of a single telegram. It
+// protects against unbounded buffering when a footer never arrives.
+const maxTelegramSize = 64 << 10
+
+// Reader reads telegrams from a continuous stream of data, like a P1 port.
+type Reader struct {
+	r    *bufio.Reader
+	opts *parseOptions
+	err  error
+	buf  bytes.Buffer
+	hash crc16 // Checksum of the frame up to and including the "!" of its footer.
+}
+
+// NewReader returns a new Reader reading telegrams from r.
+func NewReader(r io.Reader, options ...Option) *Reader {
+	opts, err := newParseOptions(options)
+
+	return &Reader{
+		r:    bufio.NewReader(r),
+		opts: opts,
+		err:  err,
+	}
+}
+
+// Next reads and parses the next telegram from the stream. Any data before the
+// header of a telegram is skipped. When a telegram cannot be read, like when it
+// cannot be parsed or decrypted, a [TelegramError] is returned and the next
+// call continues with the following telegram.
+//
+// Errors of the underlying reader are returned as is. At the end of the stream
+// Next returns io.EOF, or io.ErrUnexpectedEOF when a telegram got cut off.
+func (r *Reader) Next() (*Telegram, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if r.opts.decryptionKey != nil {
+		frame, err := r.readEncryptedFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		return r.parse(frame, nil)
+	}
+
+	frame, err := r.readFrame()
+	if err == ErrTelegramTooLarge {
+		return nil, &TelegramError{Err: err}
+	} else if err != nil {
+		return nil, err
+	}
+
+	sum := r.hash.Sum16()
+	return r.parse(frame, &sum)
+}
+
+// parse parses a frame, returning its errors as a [TelegramError].
+func (r *Reader) parse(frame string, sum *uint16) (*Telegram, error) {
+	t, err := parse(frame, sum, r.opts)
+	if err != nil {
+		return t, &TelegramError{Err: err}
+	}
+
+	return t, nil
+}
+
+// Frame returns the frame read by the last call to Next as it was read from
+// the stream, also when its telegram could not be parsed. That is the text of
+// the telegram, or the encrypted frame holding it. The frame is only valid
+// until the next call to Next.
+func (r *Reader) Frame() []byte {
+	return r.buf.Bytes()
+}
+
+// readFrame reads a single telegram from the start of its header up to and
+// including the line with its footer.
+func (r *Reader) readFrame() (string, error) {
+	// Skip everything until the start of a header.
+	for {
+		_, err := r.r.ReadSlice('/')
+		if err == nil {
+			break
+		}
+		if err != bufio.ErrBufferFull {
+			return "", err
+		}
+	}
+
+	r.buf.Reset()
+	r.buf.WriteByte('/')
+	r.hash.Reset()
+	_, _ = r.hash.WriteString("/")
+
+	// The "/" of the header is consumed already, so we start halfway a line.
+	atStart, footer := false, false
+	var h headerScanner
+
+	for {
+		line, err := r.r.ReadSlice('\n')
+		if err != nil && err != bufio.ErrBufferFull {
+			return "", unexpectedEOF(err)
+		}
+
+		if atStart {
+			footer = line[0] == '!'
+			h = headerScanner{}
+		}
+
+		// A new header means the previous telegram got cut off, start over.
+		if !footer {
+			if i := h.scan(line); i >= 0 {
+				r.buf.Reset()
+				r.hash.Reset()
+				line = line[i:]
+			}
+		}
+
+		r.buf.Write(line)
+
+		switch {
+		case !footer:
+			_, _ = r.hash.Write(line)
+		case atStart:
+			_, _ = r.hash.Write(line[:1])
+		}
+
+		if r.buf.Len() > maxTelegramSize {
+			return "", ErrTelegramTooLarge
+		}
+
+		// Long lines are read in chunks, of which only the last ends the line.
+		atStart = err == nil
+
+		if atStart && footer {
+			return r.buf.String(), nil
+		}
+	}
+}
+
+// headerScanner finds headers in the lines of a telegram, which start over a
+// telegram that got cut off. Values may hold a "/" too, so only one outside of
+// parentheses counts, or one right at the start of a value or followed by the
+// identification of a meter, as then the value got cut off.
+type headerScanner struct {
+	value bool // Whether the scanner is inside parentheses.
+	prev  byte
+}
+
+// scan returns the offset of the last header in line, or -1 if there is none.
+// Lines are scanned in order, in chunks if they are long.
+func (h *headerScanner) scan(line []byte) int {
+	header := -1
+
+	for i, c := range line {
+		switch {
+		case c == '(':
+			h.value = true
+		case c == ')':
+			h.value = false
+		case c == '/' && (!h.value || h.prev == '(' || isIdentification(line[i+1:])):
+			header, h.value = i, false
+		}
+
+		h.prev = c
+	}
+
+	return header
+}
+
+// isIdentification reports whether b starts like the identification of a
+// meter in its header, with three letters of the manufacturer and a digit.
+func isIdentification(b []byte) bool {
+	if len(b) < 4 || b[3] < '0' || b[3] > '9' {
+		return false
+	}
+
+	for _, c := range b[:3] {
+		if (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readEncryptedFrame reads a single encrypted frame holding a telegram.
+func (r *Reader) readEncryptedFrame() (string, error) {
+	for {
+		// Skip everything until the start of a frame.
+		if _, err := r.r.ReadSlice(generalGloCiphering); err == bufio.ErrBufferFull {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		titleLen, err := r.r.ReadByte()
+		if err != nil {
+			return "", unexpectedEOF(err)
+		}
+
+		// The system title is followed by the length of the rest of the frame.
+		r.buf.Reset()
+		r.buf.Write([]byte{generalGloCiphering, titleLen})
+
+		if _, err := io.CopyN(&r.buf, r.r, int64(titleLen)+3); err != nil {
+			return "", unexpectedEOF(err)
+		}
+
+		b := r.buf.Bytes()[2:]
+		if b[titleLen] != 0x82 {
+			continue
+		}
+
+		n := int(b[titleLen+1])<<8 | int(b[titleLen+2])
+		if n > maxTelegramSize {
+			continue
+		}
+
+		if _, err := io.CopyN(&r.buf, r.r, int64(n)); err != nil {
+			return "", unexpectedEOF(err)
+		}
+
+		return r.buf.String(), nil
+	}
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
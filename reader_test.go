@@ -0,0 +1,118 @@
+package dsmr
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestReader(t *testing.T) {
+	valid := "" +
+		"/header\r\n" +
+		"0-0:0.0.0()\r\n" +
+		"!75B7\r\n"
+
+	invalid := "" +
+		"/header\r\n" +
+		"0-0:0.0.0()\r\n" +
+		"!1234\r\n"
+
+	truncated := "" +
+		"/header\r\n" +
+		"0-0:0.0.0("
+
+	stream := "garbage\r\n" + valid + "more garbage" + invalid + truncated + valid + valid[:12]
+	r := NewReader(strings.NewReader(stream))
+
+	telegram, err := r.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "header", telegram.Header.Value)
+	assert.Equal(t, "75B7", telegram.Footer.Value)
+	assert.Equal(t, valid, string(r.Frame()))
+
+	_, err = r.Next()
+	assert.EqualError(t, err, "unexpected checksum \"75B7\" (expected \"1234\")")
+	assert.Equal(t, invalid, string(r.Frame()))
+
+	telegram, err = r.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "75B7", telegram.Footer.Value)
+
+	_, err = r.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReaderSlashInValue(t *testing.T) {
+	raw := "/header\r\n0-0:96.13.0(www.example.com/meter)\r\n!"
+	stream := raw + formatChecksum(Checksum([]byte(raw))) + "\r\n"
+
+	r := NewReader(strings.NewReader(stream))
+
+	telegram, err := r.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "header", telegram.Header.Value)
+	assert.Equal(t, "www.example.com/meter", telegram.Data[0].Value.(*String).Value)
+}
+
+func TestReaderCutOffValue(t *testing.T) {
+	raw := "/KFM5KAIFA-METER\r\n\r\n1-0:1.8.1(001581.123*kWh)\r\n!"
+	valid := raw + formatChecksum(Checksum([]byte(raw))) + "\r\n"
+
+	stream := "" +
+		"/header\r\n1-0:1.8.1(0001" + valid +
+		"/header\r\n1-0:1.8.1(0001*kWh)" + valid +
+		"/header\r\n0-0:96.13.0(" + valid
+
+	r := NewReader(strings.NewReader(stream))
+
+	for i := 0; i < 3; i++ {
+		telegram, err := r.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, "KFM5KAIFA-METER", telegram.Header.Value)
+		assert.Equal(t, valid, string(r.Frame()))
+	}
+
+	_, err := r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReaderOptions(t *testing.T) {
+	stream := "" +
+		"/header\r\n" +
+		"0-0:0.0.0()\r\n" +
+		"!1234\r\n"
+
+	r := NewReader(strings.NewReader(stream), VerifyChecksum(false))
+
+	telegram, err := r.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "1234", telegram.Footer.Value)
+}
+
+func TestReaderTooLarge(t *testing.T) {
+	stream := "/header\r\n" + strings.Repeat("0-0:0.0.0()\r\n", maxTelegramSize/13+1) + "!75B7\r\n"
+	r := NewReader(strings.NewReader(stream))
+
+	_, err := r.Next()
+	assert.True(t, errors.Is(err, ErrTelegramTooLarge))
+
+	var telegramErr *TelegramError
+	assert.True(t, errors.As(err, &telegramErr))
+}
+
+func TestReaderLongLines(t *testing.T) {
+	raw := "/header\r\n0-0:96.13.0(" + strings.Repeat("A", 10000) + ")\r\n!"
+	stream := raw + formatChecksum(Checksum([]byte(raw))) + "\r\n"
+
+	r := NewReader(strings.NewReader(stream))
+
+	telegram, err := r.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, 10000, len(telegram.Data[0].Value.(*String).Value))
+}
@@ -0,0 +1,312 @@
+package dsmr
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Reading is a typed view of the data in a [Telegram], where each DSMR version
+// maps onto the same fields. Fields of objects missing from the telegram are
+// left at their zero value.
+type Reading struct {
+	Version     string    // 1-3:0.2.8
+	Timestamp   time.Time // 0-0:1.0.0
+	EquipmentID string    // 0-0:96.1.1
+
+	// Meter readings in kWh.
+	ElectricityDeliveredTariff1 float64 // 1-0:1.8.1
+	ElectricityDeliveredTariff2 float64 // 1-0:1.8.2
+	ElectricityReturnedTariff1  float64 // 1-0:2.8.1
+	ElectricityReturnedTariff2  float64 // 1-0:2.8.2
+	ElectricityTariff           int     // 0-0:96.14.0
+
+	// Actual power in kW.
+	ActivePowerDelivered float64 // 1-0:1.7.0
+	ActivePowerReturned  float64 // 1-0:2.7.0
+
+	// Capacity tariff of Belgian e-MUCS meters in kW.
+	CurrentAverageDemand float64       // 1-0:1.4.0
+	PeakDemand           MonthlyPeak   // 1-0:1.6.0
+	PeakDemandHistory    []MonthlyPeak // 0-0:98.1.0
+
+	PowerFailures     int            // 0-0:96.7.21
+	LongPowerFailures int            // 0-0:96.7.9
+	PowerFailureLog   []PowerFailure // 1-0:99.97.0
+
+	VoltageSagsL1   int // 1-0:32.32.0
+	VoltageSagsL2   int // 1-0:52.32.0
+	VoltageSagsL3   int // 1-0:72.32.0
+	VoltageSwellsL1 int // 1-0:32.36.0
+	VoltageSwellsL2 int // 1-0:52.36.0
+	VoltageSwellsL3 int // 1-0:72.36.0
+
+	TextMessageCode string // 0-0:96.13.1
+	TextMessage     string // 0-0:96.13.0
+
+	// Instantaneous values per phase in V, A and kW.
+	VoltageL1              float64   // 1-0:32.7.0
+	VoltageL2              float64   // 1-0:52.7.0
+	VoltageL3              float64   // 1-0:72.7.0
+	CurrentL1              float64   // 1-0:31.7.0
+	CurrentL2              float64   // 1-0:51.7.0
+	CurrentL3              float64   // 1-0:71.7.0
+	ActivePowerDeliveredL1 float64   // 1-0:21.7.0
+	ActivePowerDeliveredL2 float64   // 1-0:41.7.0
+	ActivePowerDeliveredL3 float64   // 1-0:61.7.0
+	ActivePowerReturnedL1  float64   // 1-0:22.7.0
+	ActivePowerReturnedL2  float64   // 1-0:42.7.0
+	ActivePowerReturnedL3  float64   // 1-0:62.7.0
+	GasDelivered           float64   // 0-n:24.2.1, 0-n:24.2.3 or 0-n:24.3.0 in m3
+	GasTimestamp           time.Time // Time of the last gas capture.
+}
+
+// MonthlyPeak is the peak of the average demand in a month.
+type MonthlyPeak struct {
+	Month time.Time // Start of the month, zero for the running month.
+	Time  time.Time
+	Value float64
+}
+
+// PowerFailure is an entry of the power failure event log.
+type PowerFailure struct {
+	End      time.Time
+	Duration time.Duration
+}
+
+type readingDecoder func(r *Reading, v Value) error
+
+var readingDecoders = map[string]readingDecoder{
+	"1-3:0.2.8":   func(r *Reading, v Value) error { return decodeString(v, &r.Version) },
+	"0-0:1.0.0":   func(r *Reading, v Value) error { return decodeTime(v, &r.Timestamp) },
+	"0-0:96.1.1":  func(r *Reading, v Value) error { return decodeString(v, &r.EquipmentID) },
+	"1-0:1.8.1":   func(r *Reading, v Value) error { return decodeFloat(v, &r.ElectricityDeliveredTariff1) },
+	"1-0:1.8.2":   func(r *Reading, v Value) error { return decodeFloat(v, &r.ElectricityDeliveredTariff2) },
+	"1-0:2.8.1":   func(r *Reading, v Value) error { return decodeFloat(v, &r.ElectricityReturnedTariff1) },
+	"1-0:2.8.2":   func(r *Reading, v Value) error { return decodeFloat(v, &r.ElectricityReturnedTariff2) },
+	"0-0:96.14.0": func(r *Reading, v Value) error { return decodeInt(v, &r.ElectricityTariff) },
+	"1-0:1.7.0":   func(r *Reading, v Value) error { return decodeFloat(v, &r.ActivePowerDelivered) },
+	"1-0:2.7.0":   func(r *Reading, v Value) error { return decodeFloat(v, &r.ActivePowerReturned) },
+	"1-0:1.4.0":   func(r *Reading, v Value) error { return decodeFloat(v, &r.CurrentAverageDemand) },
+	"1-0:1.6.0":   func(r *Reading, v Value) error { return decodePeak(v, &r.PeakDemand) },
+	"0-0:98.1.0":  func(r *Reading, v Value) error { return decodePeaks(v, &r.PeakDemandHistory) },
+	"0-0:96.7.21": func(r *Reading, v Value) error { return decodeInt(v, &r.PowerFailures) },
+	"0-0:96.7.9":  func(r *Reading, v Value) error { return decodeInt(v, &r.LongPowerFailures) },
+	"1-0:99.97.0": func(r *Reading, v Value) error { return decodePowerFailures(v, &r.PowerFailureLog) },
+	"1-0:32.32.0": func(r *Reading, v Value) error { return decodeInt(v, &r.VoltageSagsL1) },
+	"1-0:52.32.0": func(r *Reading, v Value) error { return decodeInt(v, &r.VoltageSagsL2) },
+	"1-0:72.32.0": func(r *Reading, v Value) error { return decodeInt(v, &r.VoltageSagsL3) },
+	"1-0:32.36.0": func(r *Reading, v Value) error { return decodeInt(v, &r.VoltageSwellsL1) },
+	"1-0:52.36.0": func(r *Reading, v Value) error { return decodeInt(v, &r.VoltageSwellsL2) },
+	"1-0:72.36.0": func(r *Reading, v Value) error { return decodeInt(v, &r.VoltageSwellsL3) },
+	"0-0:96.13.1": func(r *Reading, v Value) error { return decodeString(v, &r.TextMessageCode) },
+	"0-0:96.13.0": func(r *Reading, v Value) error { return decodeString(v, &r.TextMessage) },
+	"1-0:32.7.0":  func(r *Reading, v Value) error { return decodeFloat(v, &r.VoltageL1) },
+	"1-0:52.7.0":  func(r *Reading, v Value) error { return decodeFloat(v, &r.VoltageL2) },
+	"1-0:72.7.0":  func(r *Reading, v Value) error { return decodeFloat(v, &r.VoltageL3) },
+	"1-0:31.7.0":  func(r *Reading, v Value) error { return decodeFloat(v, &r.CurrentL1) },
+	"1-0:51.7.0":  func(r *Reading, v Value) error { return decodeFloat(v, &r.CurrentL2) },
+	"1-0:71.7.0":  func(r *Reading, v Value) error { return decodeFloat(v, &r.CurrentL3) },
+	"1-0:21.7.0":  func(r *Reading, v Value) error { return decodeFloat(v, &r.ActivePowerDeliveredL1) },
+	"1-0:41.7.0":  func(r *Reading, v Value) error { return decodeFloat(v, &r.ActivePowerDeliveredL2) },
+	"1-0:61.7.0":  func(r *Reading, v Value) error { return decodeFloat(v, &r.ActivePowerDeliveredL3) },
+	"1-0:22.7.0":  func(r *Reading, v Value) error { return decodeFloat(v, &r.ActivePowerReturnedL1) },
+	"1-0:42.7.0":  func(r *Reading, v Value) error { return decodeFloat(v, &r.ActivePowerReturnedL2) },
+	"1-0:62.7.0":  func(r *Reading, v Value) error { return decodeFloat(v, &r.ActivePowerReturnedL3) },
+}
+
+// Decode decodes the data objects of the telegram into a [Reading].
+func (t *Telegram) Decode() (*Reading, error) {
+	r := &Reading{}
+
+	for _, obj := range t.Data {
+		decode, ok := readingDecoders[obj.Key()]
+		if !ok || isRaw(obj) {
+			continue
+		}
+
+		if err := decode(r, obj.Value); err != nil {
+			return nil, &DecodeError{OBIS: obj.Key(), Pos: obj.Pos, Err: err}
+		}
+	}
+
+	if err := r.decodeGas(t); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// decodeGas decodes the last capture of the first MBus device that is a gas meter.
+func (r *Reading) decodeGas(t *Telegram) error {
+	for channel := 1; channel <= 4; channel++ {
+		typ := t.find(fmt.Sprintf("0-%d:24.1.0", channel))
+		if typ == nil {
+			continue
+		}
+
+		var device int
+		if err := decodeInt(typ.Value, &device); err != nil {
+			return &DecodeError{OBIS: typ.Key(), Pos: typ.Pos, Err: err}
+		}
+
+		if device != 3 {
+			continue
+		}
+
+		for _, key := range []string{"0-%d:24.2.1", "0-%d:24.2.3", "0-%d:24.3.0"} {
+			obj := t.find(fmt.Sprintf(key, channel))
+			if obj == nil {
+				continue
+			}
+
+			if err := decodeCapture(obj.Value, &r.GasDelivered, &r.GasTimestamp); err != nil {
+				return &DecodeError{OBIS: obj.Key(), Pos: obj.Pos, Err: err}
+			}
+
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// find returns the first object with the given OBIS code that could be parsed.
+func (t *Telegram) find(key string) *Object {
+	for _, obj := range t.Data {
+		if obj.Key() == key && !isRaw(obj) {
+			return obj
+		}
+	}
+
+	return nil
+}
+
+// isRaw reports whether the object could not be parsed, as kept by the
+// [Lenient] option.
+func isRaw(obj *Object) bool {
+	_, ok := obj.Value.(*RawObject)
+	return ok
+}
+
+func decodeString(v Value, dst *string) error {
+	switch v := v.(type) {
+	case nil:
+	case *String:
+		*dst = v.Value
+	default:
+		return fmt.Errorf("unexpected value %T", v)
+	}
+
+	return nil
+}
+
+func decodeInt(v Value, dst *int) (err error) {
+	switch v := v.(type) {
+	case nil:
+	case *String:
+		*dst, err = strconv.Atoi(v.Value)
+	case *Number:
+		i, _ := v.Value.Int64()
+		*dst = int(i)
+	default:
+		return fmt.Errorf("unexpected value %T", v)
+	}
+
+	return err
+}
+
+func decodeFloat(v Value, dst *float64) (err error) {
+	switch v := v.(type) {
+	case nil:
+	case *String:
+		*dst, err = strconv.ParseFloat(v.Value, 64)
+	case *Number:
+		*dst, _ = v.Value.Float64()
+	case *Measurement:
+		*dst, _ = v.Value.Value.Float64()
+	default:
+		return fmt.Errorf("unexpected value %T", v)
+	}
+
+	return err
+}
+
+func decodeTime(v Value, dst *time.Time) (err error) {
+	switch v := v.(type) {
+	case nil:
+	case *Timestamp:
+		*dst, err = v.Time()
+	default:
+		return fmt.Errorf("unexpected value %T", v)
+	}
+
+	return err
+}
+
+func decodeCapture(v Value, value *float64, timestamp *time.Time) (err error) {
+	switch v := v.(type) {
+	case *LastCapture:
+		*value, _ = v.Value.Value.Value.Float64()
+		*timestamp, err = v.Timestamp.Time()
+	case *LegacyLastCapture:
+		*value, _ = v.Value.Value.Value.Float64()
+		*timestamp, err = v.Time()
+	default:
+		return fmt.Errorf("unexpected value %T", v)
+	}
+
+	return err
+}
+
+func decodePowerFailures(v Value, dst *[]PowerFailure) error {
+	log, ok := v.(*EventLog)
+	if !ok {
+		return fmt.Errorf("unexpected value %T", v)
+	}
+
+	for _, event := range log.Value {
+		end, err := event.Timestamp.Time()
+		if err != nil {
+			return err
+		}
+
+		seconds, _ := event.Value.Value.Value.Int64()
+
+		*dst = append(*dst, PowerFailure{
+			End:      end,
+			Duration: time.Duration(seconds) * time.Second,
+		})
+	}
+
+	return nil
+}
+
+func decodePeak(v Value, dst *MonthlyPeak) error {
+	return decodeCapture(v, &dst.Value, &dst.Time)
+}
+
+func decodePeaks(v Value, dst *[]MonthlyPeak) error {
+	log, ok := v.(*PeakLog)
+	if !ok {
+		return fmt.Errorf("unexpected value %T", v)
+	}
+
+	for _, peak := range log.Value {
+		month, err := peak.Month.Time()
+		if err != nil {
+			return err
+		}
+
+		t, err := peak.Timestamp.Time()
+		if err != nil {
+			return err
+		}
+
+		value, _ := peak.Value.Value.Value.Float64()
+
+		*dst = append(*dst, MonthlyPeak{Month: month, Time: t, Value: value})
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package dsmr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+const telegramV22 = "" +
+	"/ISk5\\2MT382-1004\r\n" +
+	"\r\n" +
+	"0-0:96.1.1(00000000000000)\r\n" +
+	"1-0:1.8.1(00001.001*kWh)\r\n" +
+	"1-0:1.8.2(00001.001*kWh)\r\n" +
+	"1-0:2.8.1(00001.001*kWh)\r\n" +
+	"1-0:2.8.2(00001.001*kWh)\r\n" +
+	"0-0:96.14.0(0001)\r\n" +
+	"1-0:1.7.0(0001.01*kW)\r\n" +
+	"1-0:2.7.0(0000.00*kW)\r\n" +
+	"0-0:17.0.0(0999.00*kW)\r\n" +
+	"0-0:96.3.10(1)\r\n" +
+	"0-0:96.13.1()\r\n" +
+	"0-0:96.13.0()\r\n" +
+	"0-1:24.1.0(3)\r\n" +
+	"0-1:96.1.0(000000000000)\r\n" +
+	"0-1:24.3.0(161107190000)(00)(60)(1)(0-1:24.2.1)(m3)\r\n" +
+	"(00001.001)\r\n" +
+	"0-1:24.4.0(1)\r\n" +
+	"!\r\n"
+
+const telegramV42 = "" +
+	"/KFM5KAIFA-METER\r\n" +
+	"\r\n" +
+	"1-3:0.2.8(42)\r\n" +
+	"0-0:1.0.0(161113205757W)\r\n" +
+	"0-0:96.1.1(3960221976967177082151037881335713)\r\n" +
+	"1-0:1.8.1(001581.123*kWh)\r\n" +
+	"1-0:1.8.2(001435.706*kWh)\r\n" +
+	"1-0:2.8.1(000000.000*kWh)\r\n" +
+	"1-0:2.8.2(000000.000*kWh)\r\n" +
+	"0-0:96.14.0(0002)\r\n" +
+	"1-0:1.7.0(02.027*kW)\r\n" +
+	"1-0:2.7.0(00.000*kW)\r\n" +
+	"0-0:96.7.21(00015)\r\n" +
+	"0-0:96.7.9(00007)\r\n" +
+	"1-0:99.97.0(3)(0-0:96.7.19)(000104180320W)(0000237126*s)(000101000001W)" +
+	"(2147583646*s)(000102000003W)(2317482647*s)\r\n" +
+	"1-0:32.32.0(00000)\r\n" +
+	"1-0:52.32.0(00000)\r\n" +
+	"1-0:72.32.0(00000)\r\n" +
+	"1-0:32.36.0(00000)\r\n" +
+	"1-0:52.36.0(00000)\r\n" +
+	"1-0:72.36.0(00000)\r\n" +
+	"0-0:96.13.1()\r\n" +
+	"0-0:96.13.0()\r\n" +
+	"1-0:31.7.0(000*A)\r\n" +
+	"1-0:51.7.0(006*A)\r\n" +
+	"1-0:71.7.0(002*A)\r\n" +
+	"1-0:21.7.0(00.170*kW)\r\n" +
+	"1-0:22.7.0(00.000*kW)\r\n" +
+	"1-0:41.7.0(01.247*kW)\r\n" +
+	"1-0:42.7.0(00.000*kW)\r\n" +
+	"1-0:61.7.0(00.209*kW)\r\n" +
+	"1-0:62.7.0(00.000*kW)\r\n" +
+	"0-1:24.1.0(003)\r\n" +
+	"0-1:96.1.0(4819243993373755377509728609491464)\r\n" +
+	"0-1:24.2.1(161129200000W)(00981.443*m3)\r\n" +
+	"!6796\r\n"
+
+const telegramEMUCS = "" +
+	"/FLU5\\253769484_A\r\n" +
+	"\r\n" +
+	"0-0:96.1.4(50217)\r\n" +
+	"0-0:96.1.1(3153414733313031303231363035)\r\n" +
+	"0-0:1.0.0(200512135409S)\r\n" +
+	"1-0:1.8.1(000000.034*kWh)\r\n" +
+	"1-0:1.8.2(000015.758*kWh)\r\n" +
+	"1-0:2.8.1(000000.000*kWh)\r\n" +
+	"1-0:2.8.2(000000.011*kWh)\r\n" +
+	"1-0:1.4.0(02.351*kW)\r\n" +
+	"1-0:1.6.0(200509134558S)(02.589*kW)\r\n" +
+	"0-0:98.1.0(3)(1-0:1.6.0)(1-0:1.6.0)(200501000000S)(200423192538S)(03.695*kW)" +
+	"(200401000000S)(200305122139S)(05.980*kW)(200301000000S)(200210035421W)(04.318*kW)\r\n" +
+	"0-0:96.14.0(0001)\r\n" +
+	"1-0:1.7.0(00.000*kW)\r\n" +
+	"1-0:2.7.0(00.000*kW)\r\n" +
+	"1-0:21.7.0(00.000*kW)\r\n" +
+	"1-0:41.7.0(00.000*kW)\r\n" +
+	"1-0:61.7.0(00.000*kW)\r\n" +
+	"1-0:22.7.0(00.000*kW)\r\n" +
+	"1-0:42.7.0(00.000*kW)\r\n" +
+	"1-0:62.7.0(00.000*kW)\r\n" +
+	"1-0:32.7.0(234.7*V)\r\n" +
+	"1-0:52.7.0(234.7*V)\r\n" +
+	"1-0:72.7.0(234.7*V)\r\n" +
+	"1-0:31.7.0(000.00*A)\r\n" +
+	"1-0:51.7.0(000.00*A)\r\n" +
+	"1-0:71.7.0(000.00*A)\r\n" +
+	"0-0:96.3.10(1)\r\n" +
+	"0-0:17.0.0(999.9*kW)\r\n" +
+	"1-0:31.4.0(999*A)\r\n" +
+	"0-0:96.13.0()\r\n" +
+	"0-1:24.1.0(003)\r\n" +
+	"0-1:96.1.1(37464C4F32313139303333373333)\r\n" +
+	"0-1:24.4.0(1)\r\n" +
+	"0-1:24.2.3(200512134558S)(00112.384*m3)\r\n" +
+	"!72CB\r\n"
+
+func TestDecode(t *testing.T) {
+	telegram, err := Parse(telegramV42)
+	assert.NoError(t, err)
+
+	reading, err := telegram.Decode()
+	assert.NoError(t, err)
+
+	cet := time.FixedZone("CET", 60*60)
+
+	assert.Equal(t, "42", reading.Version)
+	assert.True(t, time.Date(2016, 11, 13, 20, 57, 57, 0, cet).Equal(reading.Timestamp))
+	assert.Equal(t, 1581.123, reading.ElectricityDeliveredTariff1)
+	assert.Equal(t, 1435.706, reading.ElectricityDeliveredTariff2)
+	assert.Equal(t, 2, reading.ElectricityTariff)
+	assert.Equal(t, 2.027, reading.ActivePowerDelivered)
+	assert.Equal(t, 15, reading.PowerFailures)
+	assert.Equal(t, 7, reading.LongPowerFailures)
+	assert.Equal(t, 3, len(reading.PowerFailureLog))
+	assert.True(t, time.Date(2000, 1, 4, 18, 3, 20, 0, cet).Equal(reading.PowerFailureLog[0].End))
+	assert.Equal(t, 237126*time.Second, reading.PowerFailureLog[0].Duration)
+	assert.Equal(t, 6.0, reading.CurrentL2)
+	assert.Equal(t, 1.247, reading.ActivePowerDeliveredL2)
+	assert.Equal(t, 981.443, reading.GasDelivered)
+	assert.True(t, time.Date(2016, 11, 29, 20, 0, 0, 0, cet).Equal(reading.GasTimestamp))
+}
+
+func TestDecodeLegacy(t *testing.T) {
+	telegram, err := Parse(telegramV22)
+	assert.NoError(t, err)
+
+	reading, err := telegram.Decode()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "", reading.Version)
+	assert.Equal(t, "00000000000000", reading.EquipmentID)
+	assert.Equal(t, 1.001, reading.ElectricityDeliveredTariff1)
+	assert.Equal(t, 1.01, reading.ActivePowerDelivered)
+	assert.Equal(t, 1.001, reading.GasDelivered)
+	assert.Equal(t, 2016, reading.GasTimestamp.Year())
+}
+
+func TestDecodeError(t *testing.T) {
+	telegram, err := Parse("/header\r\n1-0:1.8.1(161113205757W)\r\n!\r\n")
+	assert.NoError(t, err)
+
+	_, err = telegram.Decode()
+	assert.EqualError(t, err, "2:1: cannot decode 1-0:1.8.1: unexpected value *dsmr.Timestamp")
+}
+
+func TestDecodeEMUCS(t *testing.T) {
+	telegram, err := Parse(telegramEMUCS)
+	assert.NoError(t, err)
+
+	reading, err := telegram.Decode()
+	assert.NoError(t, err)
+
+	cest := time.FixedZone("CEST", 2*60*60)
+
+	assert.Equal(t, 2.351, reading.CurrentAverageDemand)
+	assert.Equal(t, 2.589, reading.PeakDemand.Value)
+	assert.True(t, time.Date(2020, 5, 9, 13, 45, 58, 0, cest).Equal(reading.PeakDemand.Time))
+	assert.True(t, reading.PeakDemand.Month.IsZero())
+	assert.Equal(t, 3, len(reading.PeakDemandHistory))
+	assert.True(t, time.Date(2020, 5, 1, 0, 0, 0, 0, cest).Equal(reading.PeakDemandHistory[0].Month))
+	assert.True(t, time.Date(2020, 4, 23, 19, 25, 38, 0, cest).Equal(reading.PeakDemandHistory[0].Time))
+	assert.Equal(t, 3.695, reading.PeakDemandHistory[0].Value)
+	assert.Equal(t, 4.318, reading.PeakDemandHistory[2].Value)
+	assert.Equal(t, 112.384, reading.GasDelivered)
+}
@@ -0,0 +1,95 @@
+package dsmr
+
+import (
+	"encoding/hex"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Text is a text value of a telegram, like an equipment identifier or a text
+// message. Meters of DSMR 3.0 and later send these hex encoded.
+type Text struct {
+	Raw   string // Value as it appears in the telegram.
+	Value string // Decoded value, or the raw value if it is not hex encoded.
+}
+
+func (t Text) String() string { return t.Value }
+
+// DecodeText decodes a text value of a telegram of the given version. Values
+// of DSMR 2.2 are never hex encoded, and of other versions only decoded when
+// they are valid hex of readable text.
+func DecodeText(raw string, version Version) Text {
+	if version != Version22 {
+		if s, ok := decodeHexText(raw); ok {
+			return Text{Raw: raw, Value: s}
+		}
+	}
+
+	return Text{Raw: raw, Value: raw}
+}
+
+// decodeHexText decodes s from hex, and reports whether it was valid hex of
+// text without control characters other than line breaks and tabs.
+func decodeHexText(s string) (string, bool) {
+	if s == "" {
+		return "", false
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil || !utf8.Valid(b) {
+		return "", false
+	}
+
+	text := string(b)
+	for _, r := range text {
+		if unicode.IsControl(r) && r != '\t' && r != '\r' && r != '\n' {
+			return "", false
+		}
+	}
+
+	return text, true
+}
+
+// Text returns the decoded text value of the object with the given OBIS code,
+// like "0-1:96.1.0" for the equipment identifier of an MBus device. Only
+// objects of [KindText] are decoded, the values of others are returned as is.
+// It reports false if the telegram has no such object, or its value is not a
+// string.
+func (t *Telegram) Text(key string) (Text, bool) {
+	obj := t.find(key)
+	if obj == nil {
+		return Text{}, false
+	}
+
+	var raw string
+	if err := decodeString(obj.Value, &raw); err != nil {
+		return Text{}, false
+	}
+
+	if !isText(obj) {
+		return Text{Raw: raw, Value: raw}, true
+	}
+
+	return DecodeText(raw, t.Version()), true
+}
+
+// isText reports whether the object is of [KindText] in the OBIS registry.
+func isText(obj *Object) bool {
+	code, err := obj.OBIS.Code()
+	if err != nil {
+		return false
+	}
+
+	info, ok := LookupOBIS(code)
+	return ok && info.Kind == KindText
+}
+
+// EquipmentID returns the equipment identifier of the meter (0-0:96.1.1).
+func (t *Telegram) EquipmentID() (Text, bool) { return t.Text("0-0:96.1.1") }
+
+// TextMessage returns the text message of the grid operator (0-0:96.13.0).
+func (t *Telegram) TextMessage() (Text, bool) { return t.Text("0-0:96.13.0") }
+
+// TextMessageCode returns the numeric message code of the grid operator
+// (0-0:96.13.1).
+func (t *Telegram) TextMessageCode() (Text, bool) { return t.Text("0-0:96.13.1") }
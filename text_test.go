@@ -0,0 +1,82 @@
+package dsmr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		raw      string
+		version  Version
+		expected string
+	}{
+		{"4B384547303034303436333935353037", Version30, "K8EG004046395507"},
+		{"4B384547303034303436333935353037", VersionUnknown, "K8EG004046395507"},
+		{"4B384547303034303436333935353037", Version22, "4B384547303034303436333935353037"},
+		{"48656C6C6F0D0A776F726C64", Version50, "Hello\r\nworld"},
+		{"00000000000000", Version42, "00000000000000"},
+		{"3960221976967177082151037881335713", Version42, "3960221976967177082151037881335713"},
+		{"ABC", Version42, "ABC"},
+		{"", Version42, ""},
+	}
+
+	for _, test := range tests {
+		text := DecodeText(test.raw, test.version)
+		assert.Equal(t, test.raw, text.Raw)
+		assert.Equal(t, test.expected, text.String())
+	}
+}
+
+func TestTelegramText(t *testing.T) {
+	raw := strings.Replace(telegramV30, "0-0:96.3.10(1)\r\n", ""+
+		"0-0:96.3.10(1)\r\n"+
+		"0-0:96.13.1(303132333435363738)\r\n"+
+		"0-0:96.13.0(5374726F6F6D73746F72696E67)\r\n"+
+		"0-1:24.4.0(31)\r\n", 1)
+
+	telegram, err := Parse(raw)
+	assert.NoError(t, err)
+
+	id, ok := telegram.EquipmentID()
+	assert.True(t, ok)
+	assert.Equal(t, Text{Raw: "4B384547303034303436333935353037", Value: "K8EG004046395507"}, id)
+
+	code, ok := telegram.TextMessageCode()
+	assert.True(t, ok)
+	assert.Equal(t, "012345678", code.Value)
+
+	message, ok := telegram.TextMessage()
+	assert.True(t, ok)
+	assert.Equal(t, "Stroomstoring", message.Value)
+
+	mbus, ok := telegram.Text("0-1:96.1.0")
+	assert.True(t, ok)
+	assert.Equal(t, "2222ABCD123456789", mbus.Value)
+
+	// Values of objects that are not text are never decoded.
+	valve, ok := telegram.Text("0-1:24.4.0")
+	assert.True(t, ok)
+	assert.Equal(t, Text{Raw: "31", Value: "31"}, valve)
+
+	_, ok = telegram.Text("0-2:96.1.0")
+	assert.False(t, ok)
+
+	_, ok = telegram.Text("1-0:1.8.1")
+	assert.False(t, ok)
+}
+
+func TestTelegramTextLegacy(t *testing.T) {
+	telegram, err := Parse(telegramV22)
+	assert.NoError(t, err)
+
+	id, ok := telegram.EquipmentID()
+	assert.True(t, ok)
+	assert.Equal(t, "00000000000000", id.Value)
+
+	message, ok := telegram.TextMessage()
+	assert.True(t, ok)
+	assert.Equal(t, Text{}, message)
+}
@@ -0,0 +1,77 @@
+package dsmr
+
+import (
+	"time"
+)
+
+// timestampLayout is the YYMMDDhhmmss layout of timestamps in telegrams.
+const timestampLayout = "060102150405"
+
+// Location is the time zone in which the local time of timestamps is
+// interpreted. It defaults to Europe/Amsterdam, or to a fixed CET zone when the
+// time zone database is unavailable (import time/tzdata to embed it).
+var Location = loadLocation()
+
+func loadLocation() *time.Location {
+	loc, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		return time.FixedZone("CET", 60*60)
+	}
+
+	return loc
+}
+
+// Time returns the timestamp as a time in [Location].
+func (t *Timestamp) Time() (time.Time, error) {
+	return t.TimeIn(Location)
+}
+
+// TimeIn returns the timestamp as a time in the given location. When the time
+// occurs twice at the end of DST, the DST flag of the timestamp selects either
+// the summer (S) or winter (W) occurrence.
+func (t *Timestamp) TimeIn(loc *time.Location) (time.Time, error) {
+	return parseTime(t.Value, loc, &t.DST)
+}
+
+// Time returns the timestamp of the capture as a time in [Location].
+func (l *LegacyLastCapture) Time() (time.Time, error) {
+	return l.TimeIn(Location)
+}
+
+// TimeIn returns the timestamp of the capture as a time in the given location.
+// Legacy timestamps lack a DST flag, so when the time occurs twice at the end
+// of DST the first (summer) occurrence is returned.
+func (l *LegacyLastCapture) TimeIn(loc *time.Location) (time.Time, error) {
+	return parseTime(l.Timestamp.Value, loc, nil)
+}
+
+// parseTime parses a timestamp in loc, where dst tells apart the two
+// occurrences of the same local time at the end of DST when given.
+func parseTime(value string, loc *time.Location, dst *bool) (time.Time, error) {
+	t, err := time.ParseInLocation(timestampLayout, value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// The ambiguous hour is at most an hour away from the one picked by Go.
+	var matches []time.Time
+	for _, d := range []time.Duration{-time.Hour, 0, time.Hour} {
+		if c := t.Add(d); c.Format(timestampLayout) == value {
+			matches = append(matches, c)
+		}
+	}
+
+	if len(matches) == 0 {
+		return t, nil
+	}
+
+	if dst != nil {
+		for _, m := range matches {
+			if m.IsDST() == *dst {
+				return m, nil
+			}
+		}
+	}
+
+	return matches[0], nil
+}
@@ -0,0 +1,51 @@
+package dsmr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestTimestampTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		timestamp *Timestamp
+		expected  string
+	}{
+		{"Winter", ts("161113205757", false), "2016-11-13T19:57:57Z"},
+		{"Summer", ts("160701120000", true), "2016-07-01T10:00:00Z"},
+		{"EndOfSummer", ts("161030023000", true), "2016-10-30T00:30:00Z"},
+		{"StartOfWinter", ts("161030023000", false), "2016-10-30T01:30:00Z"},
+		{"BeforeSwitch", ts("161030020000", true), "2016-10-30T00:00:00Z"},
+		{"AfterSwitch", ts("161030030000", false), "2016-10-30T02:00:00Z"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := test.timestamp.Time()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, v.UTC().Format(time.RFC3339))
+			assert.Equal(t, Location, v.Location())
+		})
+	}
+}
+
+func TestTimestampTimeIn(t *testing.T) {
+	v, err := ts("161030023000", false).TimeIn(time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, "2016-10-30T02:30:00Z", v.Format(time.RFC3339))
+
+	_, err = ts("161330023000", false).Time()
+	assert.Error(t, err)
+}
+
+func TestLegacyLastCaptureTime(t *testing.T) {
+	v, err := llc(str("160701120000"), obis("0-1:24.2.1"), lmm("00001.001", "m3")).Time()
+	assert.NoError(t, err)
+	assert.Equal(t, "2016-07-01T10:00:00Z", v.UTC().Format(time.RFC3339))
+
+	v, err = llc(str("161030023000"), obis("0-1:24.2.1"), lmm("00001.001", "m3")).Time()
+	assert.NoError(t, err)
+	assert.Equal(t, "2016-10-30T00:30:00Z", v.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,139 @@
+package dsmr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Unit is a unit of measurement used by meters.
+type Unit int
+
+const (
+	UnitUnknown Unit = iota
+	UnitWh
+	UnitKWh
+	UnitW
+	UnitKW
+	UnitV
+	UnitA
+	UnitM3
+	UnitGJ
+	UnitS
+	UnitKvarh
+	UnitKvar
+)
+
+// units holds the spelling of the units in telegrams.
+var units = [...]string{
+	UnitWh:    "Wh",
+	UnitKWh:   "kWh",
+	UnitW:     "W",
+	UnitKW:    "kW",
+	UnitV:     "V",
+	UnitA:     "A",
+	UnitM3:    "m3",
+	UnitGJ:    "GJ",
+	UnitS:     "s",
+	UnitKvarh: "kvarh",
+	UnitKvar:  "kvar",
+}
+
+// ParseUnit parses a unit as it appears in a telegram, like "kWh".
+func ParseUnit(s string) (Unit, error) {
+	for u, name := range units {
+		if name != "" && strings.EqualFold(name, s) {
+			return Unit(u), nil
+		}
+	}
+
+	return UnitUnknown, fmt.Errorf("unknown unit %q", s)
+}
+
+func (u Unit) String() string {
+	if u > UnitUnknown && int(u) < len(units) {
+		return units[u]
+	}
+
+	return fmt.Sprintf("Unit(%d)", int(u))
+}
+
+// dimension is the physical quantity of a unit. Units can only be converted
+// into units of the same dimension.
+type dimension int
+
+const (
+	dimensionNone dimension = iota
+	dimensionEnergy
+	dimensionPower
+	dimensionVoltage
+	dimensionCurrent
+	dimensionVolume
+	dimensionTime
+	dimensionReactiveEnergy
+	dimensionReactivePower
+)
+
+// scale is a unit as a fraction of the base unit of its dimension.
+type scale struct {
+	dimension dimension
+	num, den  int64
+}
+
+var scales = [...]scale{
+	UnitWh:    {dimensionEnergy, 1, 1},
+	UnitKWh:   {dimensionEnergy, 1000, 1},
+	UnitGJ:    {dimensionEnergy, 1e9, 3600}, // 1 J is 1/3600 Wh.
+	UnitW:     {dimensionPower, 1, 1},
+	UnitKW:    {dimensionPower, 1000, 1},
+	UnitV:     {dimensionVoltage, 1, 1},
+	UnitA:     {dimensionCurrent, 1, 1},
+	UnitM3:    {dimensionVolume, 1, 1},
+	UnitS:     {dimensionTime, 1, 1},
+	UnitKvarh: {dimensionReactiveEnergy, 1, 1},
+	UnitKvar:  {dimensionReactivePower, 1, 1},
+}
+
+// Convert converts a value from one unit into another, like kW into W or GJ
+// into kWh. It returns an error if the units measure different quantities.
+func Convert(v *big.Float, from, to Unit) (*big.Float, error) {
+	if from <= UnitUnknown || int(from) >= len(scales) || to <= UnitUnknown || int(to) >= len(scales) ||
+		scales[from].dimension != scales[to].dimension {
+		return nil, fmt.Errorf("cannot convert %s into %s", from, to)
+	}
+
+	prec := v.Prec()
+	if prec < 64 {
+		prec = 64
+	}
+
+	f, s := new(big.Float).SetPrec(prec), new(big.Float).SetPrec(prec)
+	f.Mul(v, s.SetInt64(scales[from].num*scales[to].den))
+	f.Quo(f, s.SetInt64(scales[from].den*scales[to].num))
+
+	return f, nil
+}
+
+// In returns the value of the measurement converted into unit.
+func (m *Measurement) In(unit Unit) (*big.Float, error) {
+	return convertMeasurement(m.Value, m.Unit, unit)
+}
+
+// In returns the value of the measurement converted into unit.
+func (m *LegacyMeasurement) In(unit Unit) (*big.Float, error) {
+	return convertMeasurement(m.Value, m.Unit, unit)
+}
+
+func convertMeasurement(value *Number, unit *String, to Unit) (*big.Float, error) {
+	if value == nil || unit == nil {
+		return nil, errors.New("incomplete measurement")
+	}
+
+	from, err := ParseUnit(unit.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return Convert(value.Value, from, to)
+}
@@ -0,0 +1,82 @@
+package dsmr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestParseUnit(t *testing.T) {
+	for _, name := range []string{"Wh", "kWh", "W", "kW", "V", "A", "m3", "GJ", "s", "kvarh", "kvar"} {
+		unit, err := ParseUnit(name)
+		assert.NoError(t, err)
+		assert.Equal(t, name, unit.String())
+	}
+
+	unit, err := ParseUnit("KWH")
+	assert.NoError(t, err)
+	assert.Equal(t, UnitKWh, unit)
+
+	_, err = ParseUnit("l")
+	assert.EqualError(t, err, `unknown unit "l"`)
+}
+
+func TestMeasurementIn(t *testing.T) {
+	tests := []struct {
+		value    string
+		unit     string
+		to       Unit
+		expected string
+	}{
+		{"1.5", "kW", UnitW, "1500"},
+		{"250", "W", UnitKW, "0.25"},
+		{"001581.123", "kWh", UnitWh, "1581123"},
+		{"1", "GJ", UnitKWh, "277.7777778"},
+		{"277.7777778", "kWh", UnitGJ, "1"},
+		{"00233", "V", UnitV, "233"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.unit+" to "+test.to.String(), func(t *testing.T) {
+			value, _, err := big.ParseFloat(test.value, 10, 64, big.ToNearestEven)
+			assert.NoError(t, err)
+
+			m := &Measurement{Value: &Number{Value: value}, Unit: &String{Value: test.unit}}
+			f, err := m.In(test.to)
+			assert.NoError(t, err)
+
+			// Conversions between GJ and kWh do not end, so the expected values
+			// are rounded.
+			expected, _, err := big.ParseFloat(test.expected, 10, 64, big.ToNearestEven)
+			assert.NoError(t, err)
+
+			diff := new(big.Float).Sub(f, expected)
+			assert.True(t, diff.Abs(diff).Cmp(big.NewFloat(1e-7)) <= 0, f.Text('f', -1))
+		})
+	}
+}
+
+func TestMeasurementInError(t *testing.T) {
+	m := &Measurement{Value: &Number{Value: big.NewFloat(1)}, Unit: &String{Value: "kW"}}
+
+	_, err := m.In(UnitKWh)
+	assert.EqualError(t, err, "cannot convert kW into kWh")
+
+	_, err = m.In(UnitUnknown)
+	assert.EqualError(t, err, "cannot convert kW into Unit(0)")
+
+	m.Unit.Value = "l"
+	_, err = m.In(UnitW)
+	assert.EqualError(t, err, `unknown unit "l"`)
+}
+
+func TestLegacyMeasurementIn(t *testing.T) {
+	telegram, err := Parse(telegramV22)
+	assert.NoError(t, err)
+
+	capture := telegram.find("0-1:24.3.0").Value.(*LegacyLastCapture)
+	f, err := capture.Value.In(UnitM3)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.001", f.Text('f', -1))
+}
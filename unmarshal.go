@@ -0,0 +1,276 @@
+package dsmr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	bigFloatType = reflect.TypeOf(big.Float{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// Unmarshal parses the telegram in str and stores the values of its objects in
+// the struct pointed to by v.
+//
+// Struct fields are mapped onto objects by a "dsmr" tag holding the OBIS code
+// of the object, optionally followed by a comma and the part of the value to
+// use:
+//
+//	type Meter struct {
+//		Delivered float64   `dsmr:"1-0:1.8.1"`
+//		Gas       float64   `dsmr:"0-1:24.2.1"`
+//		GasTime   time.Time `dsmr:"0-1:24.2.1,timestamp"`
+//		GasUnit   string    `dsmr:"0-1:24.2.1,unit"`
+//	}
+//
+// Numbers and measurements can be stored in any integer or float field, or in
+// a big.Float. Timestamps are stored in a time.Time and measurements in seconds
+// in a time.Duration. Any value can be stored in a string. Objects without a
+// field and fields without an object are left alone.
+func Unmarshal(str string, v any, options ...Option) error {
+	t, err := Parse(str, options...)
+	if err != nil {
+		return err
+	}
+
+	return t.Unmarshal(v)
+}
+
+// Unmarshal stores the values of the objects of the telegram in the struct
+// pointed to by v. See [Unmarshal] for how fields are mapped onto objects.
+func (t *Telegram) Unmarshal(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+
+	rv = rv.Elem()
+	fields := map[string][]field{}
+
+	for i := 0; i < rv.NumField(); i++ {
+		sf := rv.Type().Field(i)
+
+		tag, ok := sf.Tag.Lookup("dsmr")
+		if !ok || !sf.IsExported() {
+			continue
+		}
+
+		key, part, _ := strings.Cut(tag, ",")
+		fields[key] = append(fields[key], field{index: i, part: part})
+	}
+
+	for _, obj := range t.Data {
+		if isRaw(obj) {
+			continue
+		}
+
+		for _, f := range fields[obj.Key()] {
+			dst := rv.Field(f.index)
+
+			if err := unmarshalValue(obj.Value, f.part, dst); err != nil {
+				sf := rv.Type().Field(f.index)
+				err = fmt.Errorf("cannot unmarshal into field %s of type %s: %w", sf.Name, sf.Type, err)
+
+				return &DecodeError{OBIS: obj.Key(), Pos: obj.Pos, Err: err}
+			}
+		}
+	}
+
+	return nil
+}
+
+type field struct {
+	index int
+	part  string
+}
+
+func unmarshalValue(v Value, part string, dst reflect.Value) error {
+	v, err := selectPart(v, part)
+	if err != nil || v == nil {
+		return err
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+
+	switch dst.Type() {
+	case timeType:
+		t, err := valueTime(v)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+
+	case durationType:
+		if m, ok := v.(*Measurement); ok && m.Unit.Value != "s" {
+			return fmt.Errorf("unexpected unit %q", m.Unit.Value)
+		}
+
+		f, err := valueNumber(v)
+		if err != nil {
+			return err
+		}
+
+		seconds, _ := f.Int64()
+		dst.SetInt(int64(time.Duration(seconds) * time.Second))
+		return nil
+
+	case bigFloatType:
+		f, err := valueNumber(v)
+		if err != nil {
+			return err
+		}
+		dst.Addr().Interface().(*big.Float).Set(f)
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		s, err := valueString(v)
+		if err != nil {
+			return err
+		}
+		dst.SetString(s)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := valueNumber(v)
+		if err != nil {
+			return err
+		}
+		f64, _ := f.Float64()
+		dst.SetFloat(f64)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f, err := valueNumber(v)
+		if err != nil {
+			return err
+		}
+		if !f.IsInt() {
+			return fmt.Errorf("value %s is not an integer", f.Text('f', -1))
+		}
+		i, acc := f.Int64()
+		if acc != big.Exact || dst.OverflowInt(i) {
+			return fmt.Errorf("value %s out of range", f.Text('f', -1))
+		}
+		dst.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, err := valueNumber(v)
+		if err != nil {
+			return err
+		}
+		if !f.IsInt() {
+			return fmt.Errorf("value %s is not an integer", f.Text('f', -1))
+		}
+		u, acc := f.Uint64()
+		if acc != big.Exact || dst.OverflowUint(u) {
+			return fmt.Errorf("value %s out of range", f.Text('f', -1))
+		}
+		dst.SetUint(u)
+
+	default:
+		return errors.New("unsupported type")
+	}
+
+	return nil
+}
+
+// selectPart selects the part of a value a field is interested in.
+func selectPart(v Value, part string) (Value, error) {
+	switch part {
+	case "":
+		switch v := v.(type) {
+		case *LastCapture:
+			return v.Value, nil
+		case *LegacyLastCapture:
+			return v.Value, nil
+		}
+		return v, nil
+
+	case "timestamp":
+		switch v := v.(type) {
+		case *Timestamp:
+			return v, nil
+		case *LastCapture:
+			return v.Timestamp, nil
+		case *LegacyLastCapture:
+			return v.Timestamp, nil
+		}
+
+	case "unit":
+		switch v := v.(type) {
+		case *Measurement:
+			return v.Unit, nil
+		case *LegacyMeasurement:
+			return v.Unit, nil
+		case *LastCapture:
+			return v.Value.Unit, nil
+		case *LegacyLastCapture:
+			return v.Value.Unit, nil
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown option %q", part)
+	}
+
+	return nil, fmt.Errorf("value %T has no %s", v, part)
+}
+
+func valueNumber(v Value) (*big.Float, error) {
+	switch v := v.(type) {
+	case *Number:
+		return v.Value, nil
+	case *Measurement:
+		return v.Value.Value, nil
+	case *LegacyMeasurement:
+		return v.Value.Value, nil
+	case *String:
+		f, _, err := big.ParseFloat(v.Value, 10, 64, big.ToNearestEven)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", v.Value)
+		}
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("unexpected value %T", v)
+}
+
+func valueString(v Value) (string, error) {
+	switch v := v.(type) {
+	case *String:
+		return v.Value, nil
+	case *OBIS:
+		return v.Value, nil
+	case *Timestamp:
+		return v.Value, nil
+	case *Number:
+		return v.Value.Text('f', -1), nil
+	case *Measurement:
+		return v.Value.Value.Text('f', -1) + "*" + v.Unit.Value, nil
+	}
+
+	return "", fmt.Errorf("unexpected value %T", v)
+}
+
+func valueTime(v Value) (time.Time, error) {
+	switch v := v.(type) {
+	case *Timestamp:
+		return v.Time()
+	case *String:
+		// Legacy captures have timestamps without a DST flag.
+		return parseTime(v.Value, Location, nil)
+	}
+
+	return time.Time{}, fmt.Errorf("unexpected value %T", v)
+}
@@ -0,0 +1,72 @@
+package dsmr
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestUnmarshal(t *testing.T) {
+	var meter struct {
+		Version   int        `dsmr:"1-3:0.2.8"`
+		Timestamp time.Time  `dsmr:"0-0:1.0.0"`
+		Tariff    uint8      `dsmr:"0-0:96.14.0"`
+		Delivered float64    `dsmr:"1-0:1.8.1"`
+		Exact     *big.Float `dsmr:"1-0:1.8.1"`
+		Unit      string     `dsmr:"1-0:1.8.1,unit"`
+		Current   int        `dsmr:"1-0:51.7.0"`
+		Gas       float32    `dsmr:"0-1:24.2.1"`
+		GasTime   time.Time  `dsmr:"0-1:24.2.1,timestamp"`
+		Message   *string    `dsmr:"0-0:96.13.0"`
+		Ignored   string
+	}
+
+	err := Unmarshal(telegramV42, &meter)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 42, meter.Version)
+	assert.Equal(t, "2016-11-13T20:57:57+01:00", meter.Timestamp.Format(time.RFC3339))
+	assert.Equal(t, uint8(2), meter.Tariff)
+	assert.Equal(t, 1581.123, meter.Delivered)
+	assert.Equal(t, "1581.123", meter.Exact.Text('f', -1))
+	assert.Equal(t, "kWh", meter.Unit)
+	assert.Equal(t, 6, meter.Current)
+	assert.Equal(t, float32(981.443), meter.Gas)
+	assert.Equal(t, "2016-11-29T20:00:00+01:00", meter.GasTime.Format(time.RFC3339))
+	assert.Zero(t, meter.Message)
+}
+
+func TestUnmarshalLegacy(t *testing.T) {
+	var meter struct {
+		Gas     float64   `dsmr:"0-1:24.3.0"`
+		GasUnit string    `dsmr:"0-1:24.3.0,unit"`
+		GasTime time.Time `dsmr:"0-1:24.3.0,timestamp"`
+	}
+
+	err := Unmarshal(telegramV22, &meter)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1.001, meter.Gas)
+	assert.Equal(t, "m3", meter.GasUnit)
+	assert.Equal(t, "2016-11-07T19:00:00+01:00", meter.GasTime.Format(time.RFC3339))
+}
+
+func TestUnmarshalError(t *testing.T) {
+	var meter struct {
+		Delivered int `dsmr:"1-0:1.8.1"`
+	}
+
+	err := Unmarshal(telegramV42, &meter)
+	assert.EqualError(t, err, "6:1: cannot decode 1-0:1.8.1: cannot unmarshal into field Delivered of type int: value 1581.123 is not an integer")
+
+	var decodeErr *DecodeError
+	assert.True(t, errors.As(err, &decodeErr))
+	assert.Equal(t, "1-0:1.8.1", decodeErr.OBIS)
+	assert.Equal(t, 6, decodeErr.Pos.Line)
+
+	err = Unmarshal(telegramV42, meter)
+	assert.EqualError(t, err, "cannot unmarshal into non-pointer or non-struct struct { Delivered int \"dsmr:\\\"1-0:1.8.1\\\"\" }")
+}
@@ -0,0 +1,292 @@
+package dsmr
+
+import "fmt"
+
+// Version is a version of the DSMR standard, or of a standard based on it.
+type Version int
+
+const (
+	VersionUnknown Version = iota
+	Version22
+	Version30
+	Version40
+	Version42
+	Version50
+	VersionEMUCS  // Belgian e-MUCS, based on DSMR 5.0.
+	VersionSmarty // Luxembourgian Smarty, based on DSMR 4.2.
+)
+
+func (v Version) String() string {
+	switch v {
+	case VersionUnknown:
+		return "unknown"
+	case Version22:
+		return "2.2"
+	case Version30:
+		return "3.0"
+	case Version40:
+		return "4.0"
+	case Version42:
+		return "4.2"
+	case Version50:
+		return "5.0"
+	case VersionEMUCS:
+		return "e-MUCS"
+	case VersionSmarty:
+		return "Smarty"
+	}
+
+	return fmt.Sprintf("Version(%d)", int(v))
+}
+
+// Version infers the version of the standard the telegram follows. Telegrams
+// of DSMR 4.0 and later carry their version in 1-3:0.2.8, while DSMR 2.2 and
+// 3.0 are told apart by the hex encoded identifiers of 3.0 and its threshold
+// in A instead of kW.
+func (t *Telegram) Version() Version {
+	if t.find("0-0:96.1.4") != nil {
+		return VersionEMUCS
+	}
+
+	if t.find("0-0:42.0.0") != nil {
+		return VersionSmarty
+	}
+
+	if obj := t.find("1-3:0.2.8"); obj != nil {
+		var version string
+		if err := decodeString(obj.Value, &version); err != nil {
+			return VersionUnknown
+		}
+
+		switch version {
+		case "40":
+			return Version40
+		case "42":
+			return Version42
+		case "50":
+			return Version50
+		}
+
+		return VersionUnknown
+	}
+
+	// Only DSMR 4.0 and later have a checksum in the footer.
+	if t.Footer != nil && t.Footer.Value != "" {
+		return VersionUnknown
+	}
+
+	if obj := t.find("0-0:17.0.0"); obj != nil {
+		if m, ok := obj.Value.(*Measurement); ok && m.Unit != nil && m.Unit.Value == "A" {
+			return Version30
+		}
+	}
+
+	if obj := t.find("0-0:96.1.1"); obj != nil {
+		var id string
+		if err := decodeString(obj.Value, &id); err == nil && isHexText(id) {
+			return Version30
+		}
+	}
+
+	return Version22
+}
+
+// isHexText reports whether s is text encoded as hex, like the identifiers and
+// messages of DSMR 3.0 and later.
+func isHexText(s string) bool {
+	_, ok := decodeHexText(s)
+	return ok
+}
+
+// Profile holds the rules a telegram of a version has to follow, as checked by
+// [Telegram.Validate]. Units of objects in the OBIS registry are checked too.
+type Profile struct {
+	Version  Version
+	Required []string          // OBIS codes of the mandatory objects.
+	Units    map[string]string // Units of objects that differ per version.
+	Checksum bool              // Whether the footer holds a checksum.
+}
+
+// requiredV40 are the mandatory objects of DSMR 4.0 and 4.2.
+var requiredV40 = []string{
+	"1-3:0.2.8", "0-0:1.0.0", "0-0:96.1.1", "1-0:1.8.1", "1-0:1.8.2", "1-0:2.8.1", "1-0:2.8.2", "0-0:96.14.0",
+	"1-0:1.7.0", "1-0:2.7.0", "0-0:96.7.21", "0-0:96.7.9", "1-0:99.97.0", "1-0:32.32.0", "1-0:32.36.0",
+	"1-0:31.7.0", "1-0:21.7.0", "1-0:22.7.0",
+}
+
+var profiles = map[Version]Profile{
+	Version22: {
+		Version:  Version22,
+		Required: []string{"0-0:96.1.1", "1-0:1.8.1", "1-0:1.8.2", "1-0:2.8.1", "1-0:2.8.2", "0-0:96.14.0", "1-0:1.7.0", "1-0:2.7.0"},
+		Units:    map[string]string{"0-0:17.0.0": "kW"},
+	},
+	Version30: {
+		Version:  Version30,
+		Required: []string{"0-0:96.1.1", "1-0:1.8.1", "1-0:1.8.2", "1-0:2.8.1", "1-0:2.8.2", "0-0:96.14.0", "1-0:1.7.0", "1-0:2.7.0"},
+		Units:    map[string]string{"0-0:17.0.0": "A"},
+	},
+	Version40: {
+		Version:  Version40,
+		Required: requiredV40,
+		Units:    map[string]string{"0-0:17.0.0": "kW"},
+		Checksum: true,
+	},
+	Version42: {
+		Version:  Version42,
+		Required: requiredV40,
+		Units:    map[string]string{"0-0:17.0.0": "kW"},
+		Checksum: true,
+	},
+	Version50: {
+		Version: Version50,
+		Required: []string{
+			"1-3:0.2.8", "0-0:1.0.0", "0-0:96.1.1", "1-0:1.8.1", "1-0:1.8.2", "1-0:2.8.1", "1-0:2.8.2", "0-0:96.14.0",
+			"1-0:1.7.0", "1-0:2.7.0", "0-0:96.7.21", "0-0:96.7.9", "1-0:99.97.0", "1-0:32.32.0", "1-0:32.36.0",
+			"1-0:32.7.0", "1-0:31.7.0", "1-0:21.7.0", "1-0:22.7.0",
+		},
+		Checksum: true,
+	},
+	VersionEMUCS: {
+		Version: VersionEMUCS,
+		Required: []string{
+			"0-0:96.1.4", "0-0:96.1.1", "0-0:1.0.0", "1-0:1.8.1", "1-0:1.8.2", "1-0:2.8.1", "1-0:2.8.2", "0-0:96.14.0",
+			"1-0:1.7.0", "1-0:2.7.0", "1-0:32.7.0", "1-0:31.7.0",
+		},
+		Checksum: true,
+	},
+	VersionSmarty: {
+		Version: VersionSmarty,
+		Required: []string{
+			"1-3:0.2.8", "0-0:1.0.0", "0-0:42.0.0", "0-0:96.1.1", "1-0:1.8.0", "1-0:2.8.0", "1-0:1.7.0", "1-0:2.7.0",
+		},
+		Checksum: true,
+	},
+}
+
+// Profile returns the validation profile of the version, or nil if the
+// version is unknown. Every call returns a new copy, which can be changed
+// without affecting other profiles.
+func (v Version) Profile() *Profile {
+	p, ok := profiles[v]
+	if !ok {
+		return nil
+	}
+
+	p.Required = append([]string(nil), p.Required...)
+	if p.Units != nil {
+		units := make(map[string]string, len(p.Units))
+		for k, u := range p.Units {
+			units[k] = u
+		}
+		p.Units = units
+	}
+
+	return &p
+}
+
+// Validate checks that the telegram contains the mandatory objects of the
+// profile, that its measurements have the expected units and that its footer
+// holds a checksum if, and only if, the version has one. All problems found
+// are returned as an [ErrorList]. A nil profile is rejected with
+// [ErrUnknownVersion], so the profile of an inferred version can be passed
+// without checking it:
+//
+//	err := t.Validate(t.Version().Profile())
+func (t *Telegram) Validate(p *Profile) error {
+	if p == nil {
+		return ErrUnknownVersion
+	}
+
+	var errs ErrorList
+
+	for _, key := range p.Required {
+		if t.find(key) == nil {
+			errs = append(errs, &MissingObjectError{OBIS: key, Version: p.Version})
+		}
+	}
+
+	devices := map[uint8]int{}
+	for channel := 1; channel <= 4; channel++ {
+		if obj := t.find(fmt.Sprintf("0-%d:24.1.0", channel)); obj != nil {
+			var device int
+			if decodeInt(obj.Value, &device) == nil {
+				devices[uint8(channel)] = device
+			}
+		}
+	}
+
+	for _, obj := range t.Data {
+		if obj.Key() == "" || isRaw(obj) || obj.Value == nil {
+			continue
+		}
+
+		expect := p.unit(obj, devices)
+		if expect == "" {
+			continue
+		}
+
+		Inspect(obj.Value, func(node Node) bool {
+			var unit *String
+			switch node := node.(type) {
+			case *Measurement:
+				unit = node.Unit
+			case *LegacyMeasurement:
+				unit = node.Unit
+			default:
+				return true
+			}
+
+			if unit != nil && unit.Value != expect {
+				errs = append(errs, &UnitError{OBIS: obj.Key(), Pos: unit.Pos, Unit: unit.Value, Expect: expect})
+			}
+
+			return false
+		})
+	}
+
+	if t.Footer != nil {
+		switch {
+		case p.Checksum && t.Footer.Value == "":
+			errs = append(errs, ErrMissingChecksum)
+		case !p.Checksum && t.Footer.Value != "":
+			errs = append(errs, ErrUnexpectedChecksum)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// unit returns the expected unit of the values of an object, or an empty
+// string if it has none. Captures of gas and water meters are in m3, going by
+// the device types of the MBus channels.
+func (p *Profile) unit(obj *Object, devices map[uint8]int) string {
+	if unit, ok := p.Units[obj.Key()]; ok {
+		return unit
+	}
+
+	code, err := obj.OBIS.Code()
+	if err != nil {
+		return ""
+	}
+
+	info, ok := LookupOBIS(code)
+	if !ok {
+		return ""
+	}
+
+	if info.Unit == "" && code.Medium == 0 && code.Channel > 0 {
+		switch info.Kind {
+		case KindLastCapture, KindLegacyLastCapture:
+			// Device type 3 is a gas meter and 7 a water meter.
+			if device := devices[code.Channel]; device == 3 || device == 7 {
+				return "m3"
+			}
+		}
+	}
+
+	return info.Unit
+}
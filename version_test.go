@@ -0,0 +1,123 @@
+package dsmr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+const telegramV30 = "" +
+	"/ISk5\\2MT382-1000\r\n" +
+	"\r\n" +
+	"0-0:96.1.1(4B384547303034303436333935353037)\r\n" +
+	"1-0:1.8.1(12345.678*kWh)\r\n" +
+	"1-0:1.8.2(12345.678*kWh)\r\n" +
+	"1-0:2.8.1(12345.678*kWh)\r\n" +
+	"1-0:2.8.2(12345.678*kWh)\r\n" +
+	"0-0:96.14.0(0002)\r\n" +
+	"1-0:1.7.0(001.19*kW)\r\n" +
+	"1-0:2.7.0(000.00*kW)\r\n" +
+	"0-0:17.0.0(016*A)\r\n" +
+	"0-0:96.3.10(1)\r\n" +
+	"0-1:96.1.0(3232323241424344313233343536373839)\r\n" +
+	"0-1:24.1.0(03)\r\n" +
+	"0-1:24.3.0(090212160000)(00)(60)(1)(0-1:24.2.1)(m3)\r\n" +
+	"(00001.001)\r\n" +
+	"0-1:24.4.0(1)\r\n" +
+	"!\r\n"
+
+const telegramSmarty = "" +
+	"/Lux5\\253833635_A\r\n" +
+	"\r\n" +
+	"1-3:0.2.8(42)\r\n" +
+	"0-0:1.0.0(170102161005W)\r\n" +
+	"0-0:42.0.0(53414733303832323030303032313630)\r\n" +
+	"0-0:96.1.1(3153414733313030303030303030303030)\r\n" +
+	"1-0:1.8.0(000003.403*kWh)\r\n" +
+	"1-0:2.8.0(000000.000*kWh)\r\n" +
+	"1-0:3.8.0(000000.001*kvarh)\r\n" +
+	"1-0:4.8.0(000001.200*kvarh)\r\n" +
+	"1-0:1.7.0(00.000*kW)\r\n" +
+	"1-0:2.7.0(00.000*kW)\r\n" +
+	"!64EC\r\n"
+
+func TestVersion(t *testing.T) {
+	tests := []struct {
+		telegram string
+		expected Version
+	}{
+		{telegramV22, Version22},
+		{telegramV30, Version30},
+		{strings.Replace(telegramV42, "1-3:0.2.8(42)", "1-3:0.2.8(40)", 1), Version40},
+		{telegramV42, Version42},
+		{strings.Replace(telegramV42, "1-3:0.2.8(42)", "1-3:0.2.8(50)", 1), Version50},
+		{telegramEMUCS, VersionEMUCS},
+		{telegramSmarty, VersionSmarty},
+		{strings.Replace(telegramV42, "1-3:0.2.8(42)", "1-3:0.2.8(99)", 1), VersionUnknown},
+		{"/header\r\n0-0:0.0.0()\r\n!75B7\r\n", VersionUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected.String(), func(t *testing.T) {
+			telegram, err := Parse(test.telegram, VerifyChecksum(false))
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, telegram.Version())
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, raw := range []string{telegramV22, telegramV30, telegramV42, telegramEMUCS, telegramSmarty} {
+		telegram, err := Parse(raw, VerifyChecksum(false))
+		assert.NoError(t, err)
+
+		version := telegram.Version()
+		assert.NoError(t, telegram.Validate(version.Profile()), version.String())
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	raw := strings.NewReplacer(
+		"1-0:1.8.2(001435.706*kWh)\r\n", "",
+		"1-0:2.7.0(00.000*kW)", "1-0:2.7.0(00.000*W)",
+		"(00981.443*m3)", "(00981.443*GJ)",
+	).Replace(telegramV42)
+	raw = raw[:strings.Index(raw, "!")+1] + "\r\n"
+
+	telegram, err := Parse(raw)
+	assert.NoError(t, err)
+
+	err = telegram.Validate(Version42.Profile())
+
+	var errs ErrorList
+	assert.True(t, errors.As(err, &errs))
+	assert.Equal(t, 4, len(errs))
+	assert.EqualError(t, errs[0], "missing 1-0:1.8.2, mandatory in DSMR 4.2")
+	assert.EqualError(t, errs[1], `11:18: unexpected unit "W" of 1-0:2.7.0 (expected "kW")`)
+	assert.Contains(t, errs[2].Error(), `unexpected unit "GJ" of 0-1:24.2.1 (expected "m3")`)
+	assert.Equal(t, ErrMissingChecksum, errs[3])
+	assert.True(t, errors.Is(err, ErrMissingChecksum))
+
+	var unitErr *UnitError
+	assert.True(t, errors.As(err, &unitErr))
+	assert.Equal(t, "1-0:2.7.0", unitErr.OBIS)
+
+	assert.Equal(t, ErrUnknownVersion, telegram.Validate(nil))
+
+	telegram, err = Parse("/header\r\n0-0:0.0.0()\r\n!75B7\r\n")
+	assert.NoError(t, err)
+	assert.Equal(t, error(ErrorList{ErrUnexpectedChecksum}), telegram.Validate(&Profile{Version: Version22}))
+}
+
+func TestProfile(t *testing.T) {
+	p := Version40.Profile()
+	p.Required[0] = "0-0:0.0.0"
+	p.Units["0-0:17.0.0"] = "W"
+
+	assert.Equal(t, "1-3:0.2.8", Version40.Profile().Required[0])
+	assert.Equal(t, "1-3:0.2.8", Version42.Profile().Required[0])
+	assert.Equal(t, "kW", Version42.Profile().Units["0-0:17.0.0"])
+	assert.Zero(t, VersionUnknown.Profile())
+}
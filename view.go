@@ -0,0 +1,115 @@
+package dsmr
+
+// View is a flat view of a telegram, for when even the nodes of the AST cost
+// too much. Parsing into a view does not allocate once the view has grown to
+// the size of the telegrams, so a view should be reused for the next telegram.
+// Its strings refer to the text of the telegram, and its objects are reused by
+// the next call to [View.Parse].
+type View struct {
+	Header  string
+	Footer  string
+	Objects []ViewObject
+
+	attrs []string
+	p     fastParser
+}
+
+// ViewObject is an object in a [View], with the text between the parentheses
+// of each of its attributes. The values of legacy captures on the next line
+// are attributes too.
+type ViewObject struct {
+	OBIS       string
+	Attributes []string
+}
+
+// Parse parses a telegram into the view, and verifies its checksum when the
+// footer holds one. Attributes are split up but not parsed, so the view checks
+// the lines and tokens of the telegram only, not whether the grammar knows
+// their values.
+//
+// Invalid telegrams return the same errors as [Parse]. Telegrams that are
+// valid but do not fit a flat view return [ErrUnsupportedTelegram].
+func (v *View) Parse(str string) error {
+	v.p = fastParser{str: str, line: 1, tokens: v.p.tokens[:0], groups: v.p.groups[:0]}
+	v.Objects = v.Objects[:0]
+	v.attrs = v.attrs[:0]
+
+	header, footer, ok := v.p.scan(v.object)
+	if !ok {
+		// The grammar tells what is wrong with the telegram.
+		if _, err := Parse(str); err != nil {
+			return err
+		}
+
+		return ErrUnsupportedTelegram
+	}
+
+	v.Header = str[1:header]
+	v.Footer = str[footer+1 : v.p.lineEnd(footer)]
+
+	if v.Footer != "" {
+		h := crc16{}
+		_, _ = h.WriteString(str[:footer+1])
+
+		if !matchChecksum(v.Footer, h.crc) {
+			return &ChecksumError{Unexpected: formatChecksum(h.crc), Expect: v.Footer}
+		}
+	}
+
+	return nil
+}
+
+// Lookup returns the first object with the OBIS code.
+func (v *View) Lookup(obis string) (ViewObject, bool) {
+	for _, obj := range v.Objects {
+		if obj.OBIS == obis {
+			return obj, true
+		}
+	}
+
+	return ViewObject{}, false
+}
+
+func (v *View) object() bool {
+	start := v.p.lineStart
+	end, _, ok := v.p.readObject()
+	if !ok {
+		return false
+	}
+
+	obis := v.p.str[start:end]
+	first := len(v.attrs)
+
+	for _, g := range v.p.groups {
+		if g.start+1 == g.end && len(v.p.groups) > 1 && !allowsEmptyAttributes(obis) {
+			return false
+		}
+
+		v.attrs = append(v.attrs, v.p.str[g.start+1:g.end])
+	}
+
+	v.Objects = append(v.Objects, ViewObject{
+		OBIS:       obis,
+		Attributes: v.attrs[first:len(v.attrs):len(v.attrs)],
+	})
+
+	return true
+}
+
+// matchChecksum reports whether footer holds the checksum as formatted by
+// formatChecksum, without formatting it.
+func matchChecksum(footer string, sum uint16) bool {
+	const hex = "0123456789ABCDEF"
+
+	if len(footer) != 4 {
+		return false
+	}
+
+	for i := 0; i < 4; i++ {
+		if footer[i] != hex[sum>>(12-4*i)&0xF] {
+			return false
+		}
+	}
+
+	return true
+}
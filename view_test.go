@@ -0,0 +1,98 @@
+package dsmr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestView(t *testing.T) {
+	var v View
+	assert.NoError(t, v.Parse(telegramV42))
+
+	assert.Equal(t, "KFM5KAIFA-METER", v.Header)
+	assert.Equal(t, "6796", v.Footer)
+
+	telegram, err := Parse(telegramV42)
+	assert.NoError(t, err)
+	assert.Equal(t, len(telegram.Data), len(v.Objects))
+
+	for i, obj := range telegram.Data {
+		assert.Equal(t, obj.OBIS.Value, v.Objects[i].OBIS)
+	}
+
+	obj, ok := v.Lookup("1-0:1.8.1")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"001581.123*kWh"}, obj.Attributes)
+
+	obj, ok = v.Lookup("0-1:24.2.1")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"161129200000W", "00981.443*m3"}, obj.Attributes)
+
+	obj, ok = v.Lookup("0-0:96.13.0")
+	assert.True(t, ok)
+	assert.Equal(t, []string{""}, obj.Attributes)
+
+	_, ok = v.Lookup("1-0:99.99.0")
+	assert.False(t, ok)
+
+	// The view is reused for the next telegram.
+	assert.NoError(t, v.Parse(telegramV22))
+	assert.Equal(t, "", v.Footer)
+
+	obj, ok = v.Lookup("0-1:24.3.0")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"161107190000", "00", "60", "1", "0-1:24.2.1", "m3", "00001.001"}, obj.Attributes)
+}
+
+func TestViewErrors(t *testing.T) {
+	var v View
+
+	err := v.Parse("/header\r\n0-0:0.0.0()\r\n!1234\r\n")
+	var checksumErr *ChecksumError
+	assert.True(t, errors.As(err, &checksumErr))
+	assert.Equal(t, "75B7", checksumErr.Unexpected)
+
+	telegram := "/header\r\n1-0:1.8.1(00 1)\r\n!\r\n"
+	_, expected := Parse(telegram)
+	assert.Error(t, expected)
+	assert.Equal(t, expected, v.Parse(telegram))
+
+	telegram = "/header\r\n1-0:1.8.1(0001*kWh)()\r\n!\r\n"
+	_, expected = Parse(telegram)
+	assert.Error(t, expected)
+	assert.Equal(t, expected, v.Parse(telegram))
+
+	err = v.Parse("/header\r\n1-0:1.8.1\r\n(0001*kWh)\r\n!\r\n")
+	assert.Equal(t, ErrUnsupportedTelegram, err)
+}
+
+func TestViewAllocs(t *testing.T) {
+	var v View
+	assert.NoError(t, v.Parse(telegramV42))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := v.Parse(telegramV42); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func FuzzView(f *testing.F) {
+	for _, telegram := range []string{telegramV22, telegramV42, telegramEMUCS} {
+		f.Add(telegram)
+	}
+
+	f.Fuzz(func(t *testing.T, telegram string) {
+		var v View
+		if err := v.Parse(telegram); err != nil {
+			return
+		}
+
+		expected, err := Parse(telegram)
+		assert.NoError(t, err)
+		assert.Equal(t, len(expected.Data), len(v.Objects))
+	})
+}
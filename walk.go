@@ -0,0 +1,78 @@
+package dsmr
+
+import "reflect"
+
+// A Visitor's Visit method is invoked for each node encountered by
+// [WalkVisitor]. If the result visitor w is not nil, WalkVisitor visits each of
+// the children of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the tree of nodes in depth-first order. It starts by calling
+// fn(node); node must not be nil. If fn returns true, Walk invokes fn
+// recursively for each of the non-nil children of node. Unlike [Inspect], fn
+// is never called with nil.
+func Walk(node Node, fn func(Node) bool) {
+	if !fn(node) {
+		return
+	}
+
+	for _, child := range node.children() {
+		if !isNil(child) {
+			Walk(child, fn)
+		}
+	}
+}
+
+// WalkVisitor traverses the tree of nodes in depth-first order, like
+// [ast.Walk] of go/ast. It starts by calling v.Visit(node); node must not be
+// nil. If the visitor w returned by v.Visit(node) is not nil, WalkVisitor is
+// invoked recursively with visitor w for each of the non-nil children of node,
+// followed by a call of w.Visit(nil).
+//
+// [ast.Walk]: https://pkg.go.dev/go/ast#Walk
+func WalkVisitor(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	for _, child := range node.children() {
+		if !isNil(child) {
+			WalkVisitor(v, child)
+		}
+	}
+
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+
+	return nil
+}
+
+// Inspect traverses the tree of nodes in depth-first order, like [ast.Inspect]
+// of go/ast. It starts by calling f(node); node must not be nil. If f returns
+// true, Inspect invokes f recursively for each of the non-nil children of
+// node, followed by a call of f(nil).
+//
+// [ast.Inspect]: https://pkg.go.dev/go/ast#Inspect
+func Inspect(node Node, f func(Node) bool) {
+	WalkVisitor(inspector(f), node)
+}
+
+// isNil reports whether the node is nil, including nil pointers of the node
+// types as children may contain them for optional values.
+func isNil(node Node) bool {
+	if node == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(node)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
@@ -0,0 +1,104 @@
+package dsmr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestInspect(t *testing.T) {
+	telegram, err := Parse(telegramV42)
+	assert.NoError(t, err)
+
+	counts := map[string]int{}
+	Inspect(telegram, func(node Node) bool {
+		if node != nil {
+			counts[fmt.Sprintf("%T", node)]++
+		}
+		return true
+	})
+
+	assert.Equal(t, map[string]int{
+		"*dsmr.Telegram":    1,
+		"*dsmr.Header":      1,
+		"*dsmr.Object":      33,
+		"*dsmr.OBIS":        34,
+		"*dsmr.String":      32,
+		"*dsmr.Timestamp":   5,
+		"*dsmr.Measurement": 19,
+		"*dsmr.Number":      20,
+		"*dsmr.EventLog":    1,
+		"*dsmr.Event":       3,
+		"*dsmr.LastCapture": 1,
+		"*dsmr.Footer":      1,
+	}, counts)
+}
+
+func TestInspectPrune(t *testing.T) {
+	telegram, err := Parse(telegramV42)
+	assert.NoError(t, err)
+
+	var keys []string
+	Inspect(telegram, func(node Node) bool {
+		if entry, ok := node.(Entry); ok {
+			keys = append(keys, entry.Key())
+		}
+		// Do not descend into the values of objects.
+		_, ok := node.(*Object)
+		return !ok
+	})
+
+	assert.Equal(t, 35, len(keys))
+	assert.Equal(t, "header", keys[0])
+	assert.Equal(t, "1-3:0.2.8", keys[1])
+	assert.Equal(t, "footer", keys[34])
+}
+
+type depthVisitor struct {
+	depth int
+	max   *int
+}
+
+func (v depthVisitor) Visit(node Node) Visitor {
+	if node == nil {
+		return nil
+	}
+
+	if v.depth > *v.max {
+		*v.max = v.depth
+	}
+
+	return depthVisitor{depth: v.depth + 1, max: v.max}
+}
+
+func TestWalk(t *testing.T) {
+	telegram, err := Parse(telegramV42)
+	assert.NoError(t, err)
+
+	var numbers []string
+	Walk(telegram, func(node Node) bool {
+		assert.NotZero(t, node)
+
+		if n, ok := node.(*Number); ok {
+			numbers = append(numbers, n.Text)
+		}
+		// Do not descend into event logs.
+		_, ok := node.(*EventLog)
+		return !ok
+	})
+
+	assert.Equal(t, 16, len(numbers))
+	assert.Equal(t, "001581.123", numbers[0])
+}
+
+func TestWalkVisitor(t *testing.T) {
+	telegram, err := Parse(telegramV42)
+	assert.NoError(t, err)
+
+	var depth int
+	WalkVisitor(depthVisitor{max: &depth}, telegram)
+
+	// Telegram > Object > EventLog > Event > Measurement > Number
+	assert.Equal(t, 5, depth)
+}